@@ -37,6 +37,34 @@ const (
 	// A ConditionKindDeviceDriverReady indicates whether the device driver is discovered
 	// and connected to the network device.
 	ConditionKindDeviceDriverReady nddv1.ConditionKind = "DeviceDriverReady"
+
+	// A ConditionKindTLSCredentialsValid indicates whether the tls credentials
+	// used to connect to the network device are valid.
+	ConditionKindTLSCredentialsValid nddv1.ConditionKind = "TLSCredentialsValid"
+
+	// A ConditionKindDeviceDriverSelected indicates whether a device driver
+	// could be selected for the network node.
+	ConditionKindDeviceDriverSelected nddv1.ConditionKind = "DeviceDriverSelected"
+
+	// A ConditionKindReachable indicates whether the network device is
+	// reachable from the core before the device driver is deployed.
+	ConditionKindReachable nddv1.ConditionKind = "Reachable"
+
+	// A ConditionKindMaintenance indicates whether the network node is
+	// intentionally taken out of management.
+	ConditionKindMaintenance nddv1.ConditionKind = "Maintenance"
+
+	// A ConditionKindTerminating indicates whether the deletion of the network
+	// node is blocked because it is still in use.
+	ConditionKindTerminating nddv1.ConditionKind = "Terminating"
+
+	// A ConditionKindRollout indicates whether the device driver is rolled out
+	// to the network nodes using the device driver.
+	ConditionKindRollout nddv1.ConditionKind = "Rollout"
+
+	// A ConditionKindDeviceDriverValid indicates whether the device driver is
+	// configured consistently with the other device drivers of its kind.
+	ConditionKindDeviceDriverValid nddv1.ConditionKind = "Valid"
 )
 
 // ConditionReasons a package is or is not installed.
@@ -46,9 +74,32 @@ const (
 	ConditionReasonUnhealthy        nddv1.ConditionReason = "UnhealthyDeviceDriver"
 	ConditionReasonHealthy          nddv1.ConditionReason = "HealthyDeviceDriver"
 	ConditionReasonUnknownHealth    nddv1.ConditionReason = "UnknownDeviceDriverHealth"
+	ConditionReasonDeploying        nddv1.ConditionReason = "DeployingDeviceDriver"
 	ConditionReasonDiscoveredReady  nddv1.ConditionReason = "DeviceDriverReady"
 	ConditionReasonNotDiscovered    nddv1.ConditionReason = "UndiscoveredDeviceDriver"
 	ConditionReasonUnknownDiscovery nddv1.ConditionReason = "UnknownDeviceDriverDiscovery"
+	ConditionReasonNoTLSCredentials nddv1.ConditionReason = "NoTLSCredentials"
+	ConditionReasonValidTLS         nddv1.ConditionReason = "ValidTLSCredentials"
+	ConditionReasonExpiringTLS      nddv1.ConditionReason = "ExpiringTLSCredentials"
+	ConditionReasonExpiredTLS       nddv1.ConditionReason = "ExpiredTLSCredentials"
+	ConditionReasonInvalidTLS       nddv1.ConditionReason = "InvalidTLSCredentials"
+	ConditionReasonSelected         nddv1.ConditionReason = "SelectedDeviceDriver"
+	ConditionReasonNotSelected      nddv1.ConditionReason = "UnselectedDeviceDriver"
+	ConditionReasonUnknownKind      nddv1.ConditionReason = "UnknownDeviceDriverKind"
+	ConditionReasonReachable        nddv1.ConditionReason = "ReachableNetworkNode"
+	ConditionReasonUnreachable      nddv1.ConditionReason = "UnreachableNetworkNode"
+	ConditionReasonInMaintenance    nddv1.ConditionReason = "InMaintenance"
+	ConditionReasonNoMaintenance    nddv1.ConditionReason = "NotInMaintenance"
+	ConditionReasonSuspended        nddv1.ConditionReason = "SuspendedDeviceDriver"
+	ConditionReasonInUse            nddv1.ConditionReason = "InUse"
+	ConditionReasonRolloutComplete  nddv1.ConditionReason = "RolloutComplete"
+	ConditionReasonRolloutCanary    nddv1.ConditionReason = "RolloutCanary"
+	ConditionReasonRolloutProgress  nddv1.ConditionReason = "RolloutProgressing"
+	ConditionReasonRolloutPaused    nddv1.ConditionReason = "RolloutPaused"
+	ConditionReasonRolloutHalted    nddv1.ConditionReason = "RolloutHalted"
+	ConditionReasonValid            nddv1.ConditionReason = "ValidDeviceDriver"
+	ConditionReasonInvalid          nddv1.ConditionReason = "InvalidDeviceDriver"
+	ConditionReasonConflicting      nddv1.ConditionReason = "ConflictingDeviceDriver"
 )
 
 // Unhealthy indicates that the device driver is unhealthy.
@@ -81,6 +132,40 @@ func UnknownHealth() nddv1.Condition {
 	}
 }
 
+// DeviceDriverDeploying indicates that the device driver is deployed but not
+// yet available.
+func DeviceDriverDeploying() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindDeviceDriverHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonDeploying,
+	}
+}
+
+// DeviceDriverSuspended indicates that the device driver is scaled to zero
+// since the network node is in maintenance.
+func DeviceDriverSuspended() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindDeviceDriverHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonSuspended,
+	}
+}
+
+// DeviceDriverPodFailure indicates that the pod of the device driver fails,
+// the reason is the reason of the pod or container failure, e.g.
+// ImagePullBackOff, CrashLoopBackOff or OOMKilled.
+func DeviceDriverPodFailure(reason string) nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindDeviceDriverHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             nddv1.ConditionReason(reason),
+	}
+}
+
 // NotConfigured indicates that the device driver is waiting to be
 // transitioned to a ready state.
 func NotConfigured() nddv1.Condition {
@@ -131,3 +216,226 @@ func UnknownDiscovery() nddv1.Condition {
 		Reason:             ConditionReasonUnknownDiscovery,
 	}
 }
+
+// NoTLSCredentials indicates that the network node does not use tls credentials.
+func NoTLSCredentials() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindTLSCredentialsValid,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonNoTLSCredentials,
+	}
+}
+
+// TLSCredentialsValid indicates that the tls credentials are valid.
+func TLSCredentialsValid() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindTLSCredentialsValid,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonValidTLS,
+	}
+}
+
+// TLSCredentialsExpiring indicates that the tls credentials are valid, but the
+// certificate expires soon.
+func TLSCredentialsExpiring() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindTLSCredentialsValid,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonExpiringTLS,
+	}
+}
+
+// TLSCredentialsExpired indicates that the certificate of the tls credentials
+// is expired.
+func TLSCredentialsExpired() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindTLSCredentialsValid,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonExpiredTLS,
+	}
+}
+
+// TLSCredentialsInvalid indicates that the tls credentials are invalid.
+func TLSCredentialsInvalid() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindTLSCredentialsValid,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonInvalidTLS,
+	}
+}
+
+// DeviceDriverSelected indicates that a device driver is selected for the
+// network node.
+func DeviceDriverSelected() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindDeviceDriverSelected,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonSelected,
+	}
+}
+
+// DeviceDriverNotSelected indicates that no device driver could be selected for
+// the network node, e.g. because of conflicting device drivers.
+func DeviceDriverNotSelected() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindDeviceDriverSelected,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonNotSelected,
+	}
+}
+
+// UnknownDeviceDriverKind indicates that no device driver could be selected for
+// the network node, since the device driver kind is unknown.
+func UnknownDeviceDriverKind() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindDeviceDriverSelected,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonUnknownKind,
+	}
+}
+
+// Reachable indicates that the network device is reachable.
+func Reachable() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindReachable,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonReachable,
+	}
+}
+
+// Unreachable indicates that the network device is not reachable.
+func Unreachable() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindReachable,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonUnreachable,
+	}
+}
+
+// InMaintenance indicates that the network node is intentionally taken out of
+// management.
+func InMaintenance() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindMaintenance,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonInMaintenance,
+	}
+}
+
+// NotInMaintenance indicates that the network node is managed.
+func NotInMaintenance() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindMaintenance,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonNoMaintenance,
+	}
+}
+
+// Terminating indicates that the network node is deleted, but that the
+// deletion is blocked because it is still in use.
+func Terminating() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindTerminating,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonInUse,
+	}
+}
+
+// RolloutComplete indicates that the device driver is rolled out to all
+// network nodes using the device driver.
+func RolloutComplete() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindRollout,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonRolloutComplete,
+	}
+}
+
+// RolloutCanary indicates that the device driver is rolled out to the canary
+// network nodes.
+func RolloutCanary() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindRollout,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonRolloutCanary,
+	}
+}
+
+// RolloutProgressing indicates that the device driver is rolled out to the
+// network nodes using the device driver.
+func RolloutProgressing() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindRollout,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonRolloutProgress,
+	}
+}
+
+// RolloutPaused indicates that the rollout of the device driver is paused.
+func RolloutPaused() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindRollout,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonRolloutPaused,
+	}
+}
+
+// RolloutHalted indicates that the rollout of the device driver is halted
+// because network nodes failed to become healthy.
+func RolloutHalted() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindRollout,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonRolloutHalted,
+	}
+}
+
+// DeviceDriverValid indicates that the device driver is configured
+// consistently.
+func DeviceDriverValid() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindDeviceDriverValid,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonValid,
+	}
+}
+
+// DeviceDriverInvalid indicates that the device driver is misconfigured.
+func DeviceDriverInvalid() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindDeviceDriverValid,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonInvalid,
+	}
+}
+
+// DeviceDriverConflicting indicates that the device driver conflicts with other
+// device drivers of its kind.
+func DeviceDriverConflicting() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindDeviceDriverValid,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonConflicting,
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// DeviceDriverConfigVersion is the version of the config document the core
+// renders for the device drivers.
+const DeviceDriverConfigVersion = "v1"
+
+// DeviceDriverConfig is the config document the core renders in the device
+// driver configmap under the ConfigmapJsonConfig key. The configmap is mounted
+// in the device driver pod at ConfigmapMountPath.
+type DeviceDriverConfig struct {
+	// Version of the config document
+	Version string `json:"version"`
+
+	// NetworkNodeName is the name of the network node served by the device driver
+	NetworkNodeName string `json:"networkNodeName"`
+
+	// DeviceDriverKind is the kind of the device driver
+	DeviceDriverKind DeviceDriverKind `json:"deviceDriverKind"`
+
+	// GrpcServerPort is the port of the grpc server of the device driver
+	GrpcServerPort int `json:"grpcServerPort"`
+
+	// Target holds the details how the device driver connects to the network node
+	Target DeviceDriverTargetConfig `json:"target"`
+
+	// ServerTLS holds the serving certificate of the grpc server, the grpc
+	// server runs without TLS when it is not set
+	ServerTLS *DeviceDriverServerTLSConfig `json:"serverTLS,omitempty"`
+}
+
+// DeviceDriverServerTLSConfig holds the paths of the certificate files of the
+// grpc server in the device driver pod. The certificates are issued by the
+// certificate authority of the core, which also issues the client certificates
+// of the providers.
+type DeviceDriverServerTLSConfig struct {
+	// CA is the path of the certificate file of the certificate authority, used
+	// to verify the client certificates
+	CA string `json:"ca"`
+
+	// Cert is the path of the serving certificate file
+	Cert string `json:"cert"`
+
+	// Key is the path of the private key file of the serving certificate
+	Key string `json:"key"`
+}
+
+// DeviceDriverTargetConfig holds the target details of the device driver config
+// document.
+type DeviceDriverTargetConfig struct {
+	// Address holds the IP:port for accessing the network node
+	Address string `json:"address"`
+
+	// Proxy used to communicate to the target network node
+	Proxy string `json:"proxy,omitempty"`
+
+	// ProxyCredentials is the path of the directory in the device driver pod
+	// holding the username and password files of the proxy
+	ProxyCredentials string `json:"proxyCredentials,omitempty"`
+
+	// Encoding defines the gnmi encoding
+	Encoding string `json:"encoding,omitempty"`
+
+	// SkipVerify disables verification of server certificates
+	SkipVerify bool `json:"skipVerify"`
+
+	// Insecure runs the communication in an insecure manner
+	Insecure bool `json:"insecure"`
+
+	// Credentials is the path of the directory in the device driver pod holding
+	// the username and password files
+	Credentials string `json:"credentials"`
+
+	// TLSCA is the path of the CA certificate file in the device driver pod
+	TLSCA string `json:"tlsCA,omitempty"`
+
+	// TLSCert is the path of the certificate file in the device driver pod
+	TLSCert string `json:"tlsCert,omitempty"`
+
+	// TLSKey is the path of the private key file in the device driver pod
+	TLSKey string `json:"tlsKey,omitempty"`
+}
+
+// DeviceDriverShardConfig is the config document the core renders in the
+// configmap of a shard of a sharded device driver under the ConfigmapJsonConfig
+// key. The credentials, tls and proxy credentials paths of the targets are
+// directories per network node.
+type DeviceDriverShardConfig struct {
+	// Version of the config document
+	Version string `json:"version"`
+
+	// Shard is the name of the shard
+	Shard string `json:"shard"`
+
+	// DeviceDriverKind is the kind of the device driver
+	DeviceDriverKind DeviceDriverKind `json:"deviceDriverKind"`
+
+	// GrpcServerPort is the port of the grpc server of the shard
+	GrpcServerPort int `json:"grpcServerPort"`
+
+	// Targets are the network nodes served by the shard
+	Targets []DeviceDriverShardTargetConfig `json:"targets"`
+
+	// ServerTLS holds the serving certificate of the grpc server, the grpc
+	// server runs without TLS when it is not set
+	ServerTLS *DeviceDriverServerTLSConfig `json:"serverTLS,omitempty"`
+}
+
+// DeviceDriverShardTargetConfig holds the details of a network node served by
+// a shard.
+type DeviceDriverShardTargetConfig struct {
+	// NetworkNodeName is the name of the network node
+	NetworkNodeName string `json:"networkNodeName"`
+
+	// Target holds the details how the shard connects to the network node
+	Target DeviceDriverTargetConfig `json:"target"`
+}
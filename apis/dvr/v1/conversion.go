@@ -0,0 +1,25 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// Hub marks the NetworkNode of v1 as the type the other versions of the
+// NetworkNode convert to and from.
+func (*NetworkNode) Hub() {}
+
+// Hub marks the DeviceDriver of v1 as the type the other versions of the
+// DeviceDriver convert to and from.
+func (*DeviceDriver) Hub() {}
@@ -18,6 +18,7 @@ package v1
 import (
 	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
 	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var _ Nn = &NetworkNode{}
@@ -30,6 +31,12 @@ type Nn interface {
 	GetControllerReference() nddv1.Reference
 	SetControllerReference(c nddv1.Reference)
 
+	GetNetworkNodeClassName() string
+	SetNetworkNodeClassName(n *string)
+
+	GetEffectiveNetworkNodeSpec() *NetworkNodeSpec
+	SetEffectiveNetworkNodeSpec(s *NetworkNodeSpec)
+
 	GetGrpcServerPort() int
 	SetGrpcServerPort(p *int)
 
@@ -45,6 +52,9 @@ type Nn interface {
 	GetTargetCredentialsName() string
 	SetTargetCredentialsName(c *string)
 
+	GetTargetProxyCredentialsName() string
+	SetTargetProxyCredentialsName(c *string)
+
 	GetTargetTLSCredentialsName() string
 	SetTargetTLSCredentialsName(s *string)
 
@@ -59,6 +69,39 @@ type Nn interface {
 
 	GetDeviceDetails() DeviceDetails
 	SetDeviceDetails(dd *DeviceDetails)
+
+	GetUsedNetworkNodeSpec() *NetworkNodeSpec
+	SetUsedNetworkNodeSpec(s *NetworkNodeSpec)
+
+	GetUsedDeviceDriverSpec() *DeviceDriverSpec
+	SetUsedDeviceDriverSpec(s *DeviceDriverSpec)
+
+	GetEffectiveSpecHash() string
+	SetEffectiveSpecHash(h string)
+
+	GetObservedGeneration() int64
+	SetObservedGeneration(g int64)
+
+	GetMaintenance() bool
+	SetMaintenance(m *bool)
+
+	GetDeviceDriverReference() *DeviceDriverReference
+	SetDeviceDriverReference(r *DeviceDriverReference)
+
+	GetDeviceDriverSelector() *metav1.LabelSelector
+	SetDeviceDriverSelector(s *metav1.LabelSelector)
+
+	GetBoundDeviceDriver() *DeviceDriverReference
+	SetBoundDeviceDriver(r *DeviceDriverReference)
+
+	GetUsers() int64
+	SetUsers(i int64)
+
+	GetUsedBy() []nddv1.TypedReference
+	SetUsedBy(r []nddv1.TypedReference)
+
+	GetDeviceDriverEndpoint() *DeviceDriverEndpoint
+	SetDeviceDriverEndpoint(e *DeviceDriverEndpoint)
 }
 
 // GetCondition of this Network Node.
@@ -81,8 +124,36 @@ func (nn *NetworkNode) SetControllerReference(c nddv1.Reference) {
 	nn.Status.ControllerRef = c
 }
 
+// spec returns the effective spec of the network node when it is recorded,
+// otherwise the spec of the network node with the built-in defaults.
+func (nn *NetworkNode) spec() *NetworkNodeSpec {
+	if nn.Status.EffectiveNetworkNodeSpec != nil {
+		return nn.Status.EffectiveNetworkNodeSpec
+	}
+	return EffectiveNetworkNodeSpec(&nn.Spec, nil)
+}
+
+func (nn *NetworkNode) GetNetworkNodeClassName() string {
+	if nn.Spec.NetworkNodeClassName == nil {
+		return ""
+	}
+	return *nn.Spec.NetworkNodeClassName
+}
+
+func (nn *NetworkNode) SetNetworkNodeClassName(n *string) {
+	nn.Spec.NetworkNodeClassName = n
+}
+
+func (nn *NetworkNode) GetEffectiveNetworkNodeSpec() *NetworkNodeSpec {
+	return nn.Status.EffectiveNetworkNodeSpec
+}
+
+func (nn *NetworkNode) SetEffectiveNetworkNodeSpec(s *NetworkNodeSpec) {
+	nn.Status.EffectiveNetworkNodeSpec = s
+}
+
 func (nn *NetworkNode) GetGrpcServerPort() int {
-	return *nn.Spec.GrpcServerPort
+	return *nn.spec().GrpcServerPort
 }
 
 func (nn *NetworkNode) SetGrpcServerPort(p *int) {
@@ -90,7 +161,7 @@ func (nn *NetworkNode) SetGrpcServerPort(p *int) {
 }
 
 func (nn *NetworkNode) GetDeviceDriverKind() DeviceDriverKind {
-	return *nn.Spec.DeviceDriverKind
+	return *nn.spec().DeviceDriverKind
 }
 
 func (nn *NetworkNode) SetDeviceDriverKind(k *DeviceDriverKind) {
@@ -98,7 +169,10 @@ func (nn *NetworkNode) SetDeviceDriverKind(k *DeviceDriverKind) {
 }
 
 func (nn *NetworkNode) GetTargetAddress() string {
-	return *nn.Spec.Target.Address
+	if nn.spec().Target.Address == nil {
+		return ""
+	}
+	return *nn.spec().Target.Address
 }
 
 func (nn *NetworkNode) SetTargetAddress(a *string) {
@@ -106,7 +180,10 @@ func (nn *NetworkNode) SetTargetAddress(a *string) {
 }
 
 func (nn *NetworkNode) GetTargetProxy() string {
-	return *nn.Spec.Target.Proxy
+	if nn.spec().Target.Proxy == nil {
+		return ""
+	}
+	return *nn.spec().Target.Proxy
 }
 
 func (nn *NetworkNode) SetTargetProxy(p *string) {
@@ -114,15 +191,32 @@ func (nn *NetworkNode) SetTargetProxy(p *string) {
 }
 
 func (nn *NetworkNode) GetTargetCredentialsName() string {
-	return *nn.Spec.Target.CredentialsName
+	if nn.spec().Target.CredentialsName == nil {
+		return ""
+	}
+	return *nn.spec().Target.CredentialsName
 }
 
 func (nn *NetworkNode) SetTargetCredentialsName(c *string) {
 	nn.Spec.Target.CredentialsName = c
 }
 
+func (nn *NetworkNode) GetTargetProxyCredentialsName() string {
+	if nn.spec().Target.ProxyCredentialsName == nil {
+		return ""
+	}
+	return *nn.spec().Target.ProxyCredentialsName
+}
+
+func (nn *NetworkNode) SetTargetProxyCredentialsName(c *string) {
+	nn.Spec.Target.ProxyCredentialsName = c
+}
+
 func (nn *NetworkNode) GetTargetTLSCredentialsName() string {
-	return *nn.Spec.Target.TLSCredentialsName
+	if nn.spec().Target.TLSCredentialsName == nil {
+		return ""
+	}
+	return *nn.spec().Target.TLSCredentialsName
 }
 
 func (nn *NetworkNode) SetTargetTLSCredentialsName(c *string) {
@@ -130,7 +224,10 @@ func (nn *NetworkNode) SetTargetTLSCredentialsName(c *string) {
 }
 
 func (nn *NetworkNode) GetTargetSkipVerify() bool {
-	return *nn.Spec.Target.SkipVerify
+	if nn.spec().Target.SkipVerify == nil {
+		return false
+	}
+	return *nn.spec().Target.SkipVerify
 }
 
 func (nn *NetworkNode) SetTargetSkipVerify(s *bool) {
@@ -138,7 +235,10 @@ func (nn *NetworkNode) SetTargetSkipVerify(s *bool) {
 }
 
 func (nn *NetworkNode) GetTargetInsecure() bool {
-	return *nn.Spec.Target.Insecure
+	if nn.spec().Target.Insecure == nil {
+		return false
+	}
+	return *nn.spec().Target.Insecure
 }
 
 func (nn *NetworkNode) SetTargetInsecure(s *bool) {
@@ -146,7 +246,10 @@ func (nn *NetworkNode) SetTargetInsecure(s *bool) {
 }
 
 func (nn *NetworkNode) GetTargetEncoding() string {
-	return *nn.Spec.Target.Encoding
+	if nn.spec().Target.Encoding == nil {
+		return ""
+	}
+	return *nn.spec().Target.Encoding
 }
 
 func (nn *NetworkNode) SetTargetEncoding(s *string) {
@@ -160,3 +263,94 @@ func (nn *NetworkNode) GetDeviceDetails() DeviceDetails {
 func (nn *NetworkNode) SetDeviceDetails(dd *DeviceDetails) {
 	nn.Status.DeviceDetails = dd
 }
+
+func (nn *NetworkNode) GetUsedNetworkNodeSpec() *NetworkNodeSpec {
+	return nn.Status.UsedNetworkNodeSpec
+}
+
+func (nn *NetworkNode) SetUsedNetworkNodeSpec(s *NetworkNodeSpec) {
+	nn.Status.UsedNetworkNodeSpec = s
+}
+
+func (nn *NetworkNode) GetUsedDeviceDriverSpec() *DeviceDriverSpec {
+	return nn.Status.UsedDeviceDriverSpec
+}
+
+func (nn *NetworkNode) SetUsedDeviceDriverSpec(s *DeviceDriverSpec) {
+	nn.Status.UsedDeviceDriverSpec = s
+}
+
+func (nn *NetworkNode) GetEffectiveSpecHash() string {
+	return nn.Status.EffectiveSpecHash
+}
+
+func (nn *NetworkNode) SetEffectiveSpecHash(h string) {
+	nn.Status.EffectiveSpecHash = h
+}
+
+func (nn *NetworkNode) GetObservedGeneration() int64 {
+	return nn.Status.ObservedGeneration
+}
+
+func (nn *NetworkNode) SetObservedGeneration(g int64) {
+	nn.Status.ObservedGeneration = g
+}
+
+func (nn *NetworkNode) GetMaintenance() bool {
+	if nn.Spec.Maintenance == nil {
+		return false
+	}
+	return *nn.Spec.Maintenance
+}
+
+func (nn *NetworkNode) SetMaintenance(m *bool) {
+	nn.Spec.Maintenance = m
+}
+
+func (nn *NetworkNode) GetDeviceDriverReference() *DeviceDriverReference {
+	return nn.spec().DeviceDriverReference
+}
+
+func (nn *NetworkNode) SetDeviceDriverReference(r *DeviceDriverReference) {
+	nn.Spec.DeviceDriverReference = r
+}
+
+func (nn *NetworkNode) GetDeviceDriverSelector() *metav1.LabelSelector {
+	return nn.spec().DeviceDriverSelector
+}
+
+func (nn *NetworkNode) SetDeviceDriverSelector(s *metav1.LabelSelector) {
+	nn.Spec.DeviceDriverSelector = s
+}
+
+func (nn *NetworkNode) GetBoundDeviceDriver() *DeviceDriverReference {
+	return nn.Status.DeviceDriverReference
+}
+
+func (nn *NetworkNode) SetBoundDeviceDriver(r *DeviceDriverReference) {
+	nn.Status.DeviceDriverReference = r
+}
+
+func (nn *NetworkNode) GetUsers() int64 {
+	return nn.Status.Users
+}
+
+func (nn *NetworkNode) SetUsers(i int64) {
+	nn.Status.Users = i
+}
+
+func (nn *NetworkNode) GetUsedBy() []nddv1.TypedReference {
+	return nn.Status.UsedBy
+}
+
+func (nn *NetworkNode) SetUsedBy(r []nddv1.TypedReference) {
+	nn.Status.UsedBy = r
+}
+
+func (nn *NetworkNode) GetDeviceDriverEndpoint() *DeviceDriverEndpoint {
+	return nn.Status.Endpoint
+}
+
+func (nn *NetworkNode) SetDeviceDriverEndpoint(e *DeviceDriverEndpoint) {
+	nn.Status.Endpoint = e
+}
@@ -21,21 +21,64 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// NetworkNodeSpec defines the desired state of NetworkNode
+// NetworkNodeSpec defines the desired state of NetworkNode. The fields of a
+// network node without network node class get the built-in defaults from the
+// mutating webhook, the fields of a network node with network node class are
+// left unset and default to the class. Clients read the defaulted fields
+// through the getters of the network node or from the effective network node
+// spec in the status, not from the spec.
 type NetworkNodeSpec struct {
 	// Target defines the details how we connect to the network device
 	Target *TargetDetails `json:"target"`
 
-	// DeviceDriver defines the device driver details to connect to the network device
+	// NetworkNodeClassName is the name of the network node class providing the
+	// defaults of the fields the network node does not specify
+	// +optional
+	NetworkNodeClassName *string `json:"networkNodeClassName,omitempty"`
+
+	// DeviceDriver defines the device driver details to connect to the network
+	// device, defaults to the device driver kind of the network node class or
+	// gnmi
 	// +optional
-	// +kubebuilder:default=gnmi
 	DeviceDriverKind *DeviceDriverKind `json:"deviceDriverKind,omitempty"`
 
 	// GrpcServerPort defines the grpc server port to connect to the device driver
-	// from the network device provider
+	// from the network device provider, defaults to the grpc server port of the
+	// network node class or 9999
 	// +optional
-	// +kubebuilder:default=9999
 	GrpcServerPort *int `json:"grpcServerPort,omitempty"`
+
+	// Maintenance takes the network node out of management, e.g. during an RMA
+	// or a maintenance window. The device driver is scaled to zero while its
+	// configuration and the status of the network node are kept, a network
+	// node served by a shard is taken out of its shard.
+	// +optional
+	Maintenance *bool `json:"maintenance,omitempty"`
+
+	// DeviceDriverReference references the device driver used for the network
+	// node. When not specified the device driver is selected with the following
+	// precedence: the device drivers of the device driver kind matching the
+	// DeviceDriverSelector, the device driver of the device driver kind marked
+	// as default, the only device driver of the device driver kind and last the
+	// built-in device driver of the device driver kind.
+	// +optional
+	DeviceDriverReference *DeviceDriverReference `json:"deviceDriverRef,omitempty"`
+
+	// DeviceDriverSelector selects the device driver used for the network node
+	// by the labels of the device drivers of the device driver kind. The
+	// selector takes precedence over the device driver marked as default.
+	// +optional
+	DeviceDriverSelector *metav1.LabelSelector `json:"deviceDriverSelector,omitempty"`
+}
+
+// DeviceDriverReference references a device driver
+type DeviceDriverReference struct {
+	// Name of the device driver
+	Name string `json:"name"`
+
+	// Namespace of the device driver, defaults to the default namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // NetworkNodeStatus defines the observed state of NetworkNode
@@ -43,6 +86,60 @@ type NetworkNodeStatus struct {
 	nddv1.ConditionedStatus `json:",inline"`
 	ControllerRef           nddv1.Reference `json:"controllerRef,omitempty"`
 	DeviceStatus            `json:",inline"`
+
+	// DeviceDriverReference references the device driver the network node is
+	// bound to, it is empty when the built-in device driver is used
+	DeviceDriverReference *DeviceDriverReference `json:"deviceDriverRef,omitempty"`
+
+	// Users is the number of network node usages referencing the network node
+	Users int64 `json:"users,omitempty"`
+
+	// UsedBy references the resources using the network node
+	UsedBy []nddv1.TypedReference `json:"usedBy,omitempty"`
+
+	// Endpoint is the endpoint of the device driver serving the network node,
+	// it is empty when no device driver is deployed for the network node
+	Endpoint *DeviceDriverEndpoint `json:"endpoint,omitempty"`
+
+	// EffectiveNetworkNodeSpec is the spec of the network node merged with the
+	// defaults of its network node class and the built-in defaults
+	EffectiveNetworkNodeSpec *NetworkNodeSpec `json:"effectiveNetworkNodeSpec,omitempty"`
+}
+
+// DeviceDriverEndpoint is the endpoint of the grpc server of the device driver
+// serving the network node, through which the providers reach the network node.
+type DeviceDriverEndpoint struct {
+	// Host is the dns name of the device driver
+	Host string `json:"host"`
+
+	// Port is the port of the grpc server of the device driver
+	Port int `json:"port"`
+
+	// ServerName is the name to verify the serving certificate of the device
+	// driver with, it is empty when the device driver serves plain grpc
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// CA references the certificate of the certificate authority that issued
+	// the serving certificate of the device driver
+	// +optional
+	CA *CAReference `json:"ca,omitempty"`
+
+	// Ready is true when the device driver serving the network node is healthy
+	Ready bool `json:"ready"`
+}
+
+// CAReference references the key of a configmap holding the certificate of a
+// certificate authority.
+type CAReference struct {
+	// Namespace of the configmap
+	Namespace string `json:"namespace"`
+
+	// Name of the configmap
+	Name string `json:"name"`
+
+	// Key of the certificate in the configmap
+	Key string `json:"key"`
 }
 
 // +kubebuilder:object:root=true
@@ -55,15 +152,20 @@ type NetworkNodeStatus struct {
 // +kubebuilder:printcolumn:name="CONFIGURED",type="string",JSONPath=".status.conditions[?(@.kind=='DeviceDriverConfigured')].status"
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.kind=='DeviceDriverReady')].status"
 // +kubebuilder:printcolumn:name="ADDRESS",type="string",JSONPath=".spec.target.address",description="address to connect to the device'"
-// +kubebuilder:printcolumn:name="CONN-KIND",type="string",JSONPath=".spec.deviceDriverKind",description="Kind of communication type to the device"
+// +kubebuilder:printcolumn:name="CONN-KIND",type="string",JSONPath=".status.effectiveNetworkNodeSpec.deviceDriverKind",description="Kind of communication type to the device"
+// +kubebuilder:printcolumn:name="CLASS",type="string",JSONPath=".spec.networkNodeClassName",description="network node class of the network node",priority=1
+// +kubebuilder:printcolumn:name="DEVICEDRIVER",type="string",JSONPath=".status.deviceDriverRef.name",description="device driver the network node is bound to",priority=1
+// +kubebuilder:printcolumn:name="ENDPOINT",type="string",JSONPath=".status.endpoint.host",description="endpoint of the device driver serving the network node",priority=1
+// +kubebuilder:printcolumn:name="USERS",type="integer",JSONPath=".status.users",description="number of resources using the network node",priority=1
 // +kubebuilder:printcolumn:name="TYPE",type="string",JSONPath=".status.deviceDetails.type",description="Type of device"
 // +kubebuilder:printcolumn:name="KIND",type="string",JSONPath=".status.deviceDetails.kind",description="Kind of device"
 // +kubebuilder:printcolumn:name="SWVERSION",type="string",JSONPath=".status.deviceDetails.swVersion",description="SW version of the device"
 // +kubebuilder:printcolumn:name="MACADDRESS",type="string",JSONPath=".status.deviceDetails.macAddress",description="macAddress of the device"
 // +kubebuilder:printcolumn:name="SERIALNBR",type="string",JSONPath=".status.deviceDetails.serialNumber",description="serialNumber of the device"
-// +kubebuilder:printcolumn:name="GRPCSERVERPORT",type="string",JSONPath=".spec.grpcServerPort",description="grpc server port to connect to the devic driver"
+// +kubebuilder:printcolumn:name="GRPCSERVERPORT",type="string",JSONPath=".status.effectiveNetworkNodeSpec.grpcServerPort",description="grpc server port to connect to the devic driver"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:scope=Cluster,categories={ndd,dvr},shortName=nn
+// +kubebuilder:storageversion
 type NetworkNode struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,203 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The defaults of the network nodes that are neither specified by the network
+// node nor by its network node class.
+const (
+	DefaultDeviceDriverKind = DeviceDriverKindGnmi
+	DefaultGrpcServerPort   = 9999
+	DefaultEncoding         = "JSON_IETF"
+)
+
+// NetworkNodeClassSpec defines the defaults of the network nodes of the
+// network node class
+type NetworkNodeClassSpec struct {
+	// Target defines the defaults of the details how we connect to the network
+	// devices
+	// +optional
+	Target *TargetDefaults `json:"target,omitempty"`
+
+	// DeviceDriverKind defines the device driver kind of the network nodes
+	// +optional
+	DeviceDriverKind *DeviceDriverKind `json:"deviceDriverKind,omitempty"`
+
+	// GrpcServerPort defines the grpc server port to connect to the device
+	// drivers from the network device provider
+	// +optional
+	GrpcServerPort *int `json:"grpcServerPort,omitempty"`
+
+	// DeviceDriverReference references the device driver used for the network
+	// nodes
+	// +optional
+	DeviceDriverReference *DeviceDriverReference `json:"deviceDriverRef,omitempty"`
+
+	// DeviceDriverSelector selects the device driver used for the network
+	// nodes by the labels of the device drivers
+	// +optional
+	DeviceDriverSelector *metav1.LabelSelector `json:"deviceDriverSelector,omitempty"`
+}
+
+// TargetDefaults contains the defaults of the information necessary to
+// communicate with the network nodes, the address is specific to every
+// network node.
+type TargetDefaults struct {
+	// Proxy used to communicate to the target network nodes, as a URL with
+	// scheme socks5, socks5h or http (HTTP CONNECT), e.g. socks5://jump:1080
+	// +kubebuilder:validation:Optional
+	Proxy *string `json:"proxy,omitempty"`
+
+	// The name of the secret containing the credentials of the proxy (requires
+	// keys "username" and "password").
+	// +kubebuilder:validation:Optional
+	ProxyCredentialsName *string `json:"proxyCredentialsName,omitempty"`
+
+	// The name of the secret containing the credentials (requires
+	// keys "username" and "password").
+	// +kubebuilder:validation:Optional
+	CredentialsName *string `json:"credentialsName,omitempty"`
+
+	// The name of the secret containing the credentials (requires
+	// keys "TLSCA" and "TLSCert", " TLSKey").
+	// +kubebuilder:validation:Optional
+	TLSCredentialsName *string `json:"tlsCredentialsName,omitempty"`
+
+	// SkipVerify disables verification of server certificates when using
+	// HTTPS to connect to the Target.
+	// +kubebuilder:validation:Optional
+	SkipVerify *bool `json:"skipVerify,omitempty"`
+
+	// Insecure runs the communication in an insecure manner
+	// +kubebuilder:validation:Optional
+	Insecure *bool `json:"insecure,omitempty"`
+
+	// Encoding defines the gnmi encoding
+	// +kubebuilder:validation:Enum=`JSON`;`BYTES`;`PROTO`;`ASCII`;`JSON_IETF`
+	// +kubebuilder:validation:Optional
+	Encoding *string `json:"encoding,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A NetworkNodeClass defines the defaults shared by the network nodes that
+// reference it by name, the fields specified by a network node override the
+// defaults of its class.
+// +kubebuilder:printcolumn:name="CONN-KIND",type="string",JSONPath=".spec.deviceDriverKind",description="Kind of communication type to the devices"
+// +kubebuilder:printcolumn:name="GRPCSERVERPORT",type="string",JSONPath=".spec.grpcServerPort",description="grpc server port to connect to the device drivers"
+// +kubebuilder:printcolumn:name="CREDENTIALS",type="string",JSONPath=".spec.target.credentialsName",description="credentials of the devices",priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={ndd,dvr},shortName=nnc
+type NetworkNodeClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NetworkNodeClassSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NetworkNodeClassList contains a list of NetworkNodeClass
+type NetworkNodeClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NetworkNodeClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NetworkNodeClass{}, &NetworkNodeClassList{})
+}
+
+// EffectiveNetworkNodeSpec returns the spec of the network node merged with
+// the defaults of its network node class, which may be nil. The fields of the
+// network node override the fields of the class and the fields specified by
+// neither get the built-in defaults.
+func EffectiveNetworkNodeSpec(spec *NetworkNodeSpec, class *NetworkNodeClassSpec) *NetworkNodeSpec {
+	s := spec.DeepCopy()
+	if s.Target == nil {
+		s.Target = &TargetDetails{}
+	}
+	if class == nil {
+		class = &NetworkNodeClassSpec{}
+	}
+	c := class.DeepCopy()
+	t := c.Target
+	if t == nil {
+		t = &TargetDefaults{}
+	}
+
+	if s.Target.Proxy == nil {
+		s.Target.Proxy = t.Proxy
+	}
+	if s.Target.ProxyCredentialsName == nil {
+		s.Target.ProxyCredentialsName = t.ProxyCredentialsName
+	}
+	if s.Target.CredentialsName == nil {
+		s.Target.CredentialsName = t.CredentialsName
+	}
+	if s.Target.TLSCredentialsName == nil {
+		s.Target.TLSCredentialsName = t.TLSCredentialsName
+	}
+	if s.Target.SkipVerify == nil {
+		s.Target.SkipVerify = t.SkipVerify
+	}
+	if s.Target.Insecure == nil {
+		s.Target.Insecure = t.Insecure
+	}
+	if s.Target.Encoding == nil {
+		s.Target.Encoding = t.Encoding
+	}
+	if s.DeviceDriverKind == nil {
+		s.DeviceDriverKind = c.DeviceDriverKind
+	}
+	if s.GrpcServerPort == nil {
+		s.GrpcServerPort = c.GrpcServerPort
+	}
+	if s.DeviceDriverReference == nil && s.DeviceDriverSelector == nil {
+		// the device driver reference and selector are defaulted together,
+		// since the reference takes precedence over the selector
+		s.DeviceDriverReference = c.DeviceDriverReference
+		s.DeviceDriverSelector = c.DeviceDriverSelector
+	}
+
+	if s.Target.SkipVerify == nil {
+		s.Target.SkipVerify = boolPtr(false)
+	}
+	if s.Target.Insecure == nil {
+		s.Target.Insecure = boolPtr(false)
+	}
+	if s.Target.Encoding == nil {
+		e := DefaultEncoding
+		s.Target.Encoding = &e
+	}
+	if s.DeviceDriverKind == nil {
+		k := DefaultDeviceDriverKind
+		s.DeviceDriverKind = &k
+	}
+	if s.GrpcServerPort == nil {
+		p := DefaultGrpcServerPort
+		s.GrpcServerPort = &p
+	}
+	return s
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -17,21 +17,66 @@ limitations under the License.
 package v1
 
 import (
+	"strings"
+
 	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
 )
 
 const (
-	ConfigmapJsonConfig      = "config.json"
-	LabelApplication         = "app"
-	LabelNetworkDeviceDriver = "ndd"
-	PrefixNetworkNode        = "ndd"
-	PrefixConfigmap          = "ndd-cm"
-	PrefixDeployment         = "ndd-dep"
-	PrefixService            = "ndd-svc"
-	Namespace                = "ndd-system"
-	NamespaceLocalK8sDNS     = Namespace + "." + "svc.cluster.local:"
+	ConfigmapJsonConfig       = "config.json"
+	ConfigmapVolume           = "config"
+	ConfigmapMountPath        = "/config"
+	AnnotationConfigHash      = "dvr.ndd.yndd.io/config-hash"
+	AnnotationSecretHash      = "dvr.ndd.yndd.io/secret-hash"
+	AnnotationSpecHash        = "dvr.ndd.yndd.io/spec-hash"
+	AnnotationRBACHash        = "dvr.ndd.yndd.io/rbac-hash"
+	AnnotationPolicyHash      = "dvr.ndd.yndd.io/network-policy-hash"
+	AnnotationDefaultDriver   = "dvr.ndd.yndd.io/is-default-device-driver"
+	AnnotationForceDelete     = "dvr.ndd.yndd.io/force-delete"
+	AnnotationDriverRevision  = "dvr.ndd.yndd.io/device-driver-revision"
+	AnnotationShard           = "dvr.ndd.yndd.io/shard"
+	PrefixCredentialsSecret   = "ndd-creds"
+	CredentialsVolume         = "credentials"
+	CredentialsMountPath      = "/credentials"
+	CredentialsUsernameKey    = "username"
+	CredentialsPasswordKey    = "password"
+	ProxyCredentialsVolume    = "proxy-credentials"
+	ProxyCredentialsMountPath = "/proxy-credentials"
+	ProxyUsernameKey          = "proxyUsername"
+	ProxyPasswordKey          = "proxyPassword"
+	PrefixTLSSecret           = "ndd-tls"
+	TLSVolume                 = "tls"
+	TLSMountPath              = "/tls"
+	TLSCAKey                  = "TLSCA"
+	TLSCertKey                = "TLSCert"
+	TLSKeyKey                 = "TLSKey"
+	PrefixServerCertSecret    = "ndd-server-cert"
+	ServerCertVolume          = "server-cert"
+	ServerCertMountPath       = "/server-cert"
+	LabelApplication          = "app"
+	LabelNetworkDeviceDriver  = "ndd"
+	LabelDeviceDriverKind     = "ddriver-kind"
+	PrefixNetworkNode         = "ndd"
+	PrefixConfigmap           = "ndd-cm"
+	PrefixDeployment          = "ndd-dep"
+	PrefixService             = "ndd-svc"
+	PrefixShard               = "ndd-shard"
+	LabelDeviceDriverName     = "dvr.ndd.yndd.io/device-driver"
+	LabelDeviceDriverNs       = "dvr.ndd.yndd.io/device-driver-namespace"
+	LabelProviderRevision     = "pkg.ndd.yndd.io/revision"
+	LabelControlPlane         = "control-plane"
+	ControlPlaneCore          = "core"
+	Namespace                 = "ndd-system"
+	DefaultNamespace          = "default"
+	NamespaceLocalK8sDNS      = Namespace + "." + "svc.cluster.local:"
 )
 
+// ServiceDNSName returns the cluster local dns name of the service in the
+// namespace.
+func ServiceDNSName(service, namespace string) string {
+	return strings.Join([]string{service, namespace, "svc", "cluster", "local"}, ".")
+}
+
 // DeviceDriverKind represents the kinds of device drivers are supported
 // by the network device driver
 type DeviceDriverKind string
@@ -51,14 +96,21 @@ type TargetDetails struct {
 	// +kubebuilder:validation:Required
 	Address *string `json:"address"`
 
-	// Proxy used to communicate to the target network node
+	// Proxy used to communicate to the target network node, as a URL with
+	// scheme socks5, socks5h or http (HTTP CONNECT), e.g. socks5://jump:1080
 	// +kubebuilder:validation:Optional
 	Proxy *string `json:"proxy,omitempty"`
 
-	// The name of the secret containing the credentials (requires
+	// The name of the secret containing the credentials of the proxy (requires
 	// keys "username" and "password").
-	// +kubebuilder:validation:Required
-	CredentialsName *string `json:"credentialsName"`
+	// +kubebuilder:validation:Optional
+	ProxyCredentialsName *string `json:"proxyCredentialsName,omitempty"`
+
+	// The name of the secret containing the credentials (requires
+	// keys "username" and "password"), defaults to the credentials of the
+	// network node class.
+	// +kubebuilder:validation:Optional
+	CredentialsName *string `json:"credentialsName,omitempty"`
 
 	// The name of the secret containing the credentials (requires
 	// keys "TLSCA" and "TLSCert", " TLSKey").
@@ -69,18 +121,16 @@ type TargetDetails struct {
 	// HTTPS to connect to the Target. This is required when the server
 	// certificate is self-signed, but is insecure because it allows a
 	// man-in-the-middle to intercept the connection.
-	// +kubebuilder:default:=false
 	// +kubebuilder:validation:Optional
 	SkipVerify *bool `json:"skpVerify,omitempty"`
 
 	// Insecure runs the communication in an insecure manner
-	// +kubebuilder:default:=false
 	// +kubebuilder:validation:Optional
 	Insecure *bool `json:"insecure,omitempty"`
 
-	// Encoding defines the gnmi encoding
+	// Encoding defines the gnmi encoding, defaults to the encoding of the
+	// network node class or JSON_IETF
 	// +kubebuilder:validation:Enum=`JSON`;`BYTES`;`PROTO`;`ASCII`;`JSON_IETF`
-	// +kubebuilder:default=JSON_IETF
 	// +kubebuilder:validation:Optional
 	Encoding *string `json:"encoding,omitempty"`
 }
@@ -135,4 +185,12 @@ type DeviceStatus struct {
 
 	// UsedDeviceDriverSpec identifies the used deviceDriver spec when installed
 	UsedDeviceDriverSpec *DeviceDriverSpec `json:"usedDeviceDriverSpec,omitempty"`
+
+	// EffectiveSpecHash is the hash of the effective device driver
+	// configuration that is rolled out
+	EffectiveSpecHash string `json:"effectiveSpecHash,omitempty"`
+
+	// ObservedGeneration is the generation of the network node of which the
+	// device driver configuration is rolled out
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
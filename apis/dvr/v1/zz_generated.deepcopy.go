@@ -23,9 +23,27 @@ package v1
 import (
 	commonv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CAReference) DeepCopyInto(out *CAReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CAReference.
+func (in *CAReference) DeepCopy() *CAReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CAReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceDetails) DeepCopyInto(out *DeviceDetails) {
 	*out = *in
@@ -77,6 +95,7 @@ func (in *DeviceDriver) DeepCopyInto(out *DeviceDriver) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDriver.
@@ -97,6 +116,47 @@ func (in *DeviceDriver) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDriverConfig) DeepCopyInto(out *DeviceDriverConfig) {
+	*out = *in
+	out.Target = in.Target
+	if in.ServerTLS != nil {
+		in, out := &in.ServerTLS, &out.ServerTLS
+		*out = new(DeviceDriverServerTLSConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDriverConfig.
+func (in *DeviceDriverConfig) DeepCopy() *DeviceDriverConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDriverConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDriverEndpoint) DeepCopyInto(out *DeviceDriverEndpoint) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(CAReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDriverEndpoint.
+func (in *DeviceDriverEndpoint) DeepCopy() *DeviceDriverEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDriverEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceDriverList) DeepCopyInto(out *DeviceDriverList) {
 	*out = *in
@@ -129,6 +189,77 @@ func (in *DeviceDriverList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDriverReference) DeepCopyInto(out *DeviceDriverReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDriverReference.
+func (in *DeviceDriverReference) DeepCopy() *DeviceDriverReference {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDriverReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDriverServerTLSConfig) DeepCopyInto(out *DeviceDriverServerTLSConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDriverServerTLSConfig.
+func (in *DeviceDriverServerTLSConfig) DeepCopy() *DeviceDriverServerTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDriverServerTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDriverShardConfig) DeepCopyInto(out *DeviceDriverShardConfig) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]DeviceDriverShardTargetConfig, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServerTLS != nil {
+		in, out := &in.ServerTLS, &out.ServerTLS
+		*out = new(DeviceDriverServerTLSConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDriverShardConfig.
+func (in *DeviceDriverShardConfig) DeepCopy() *DeviceDriverShardConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDriverShardConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDriverShardTargetConfig) DeepCopyInto(out *DeviceDriverShardTargetConfig) {
+	*out = *in
+	out.Target = in.Target
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDriverShardTargetConfig.
+func (in *DeviceDriverShardTargetConfig) DeepCopy() *DeviceDriverShardTargetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDriverShardTargetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceDriverSpec) DeepCopyInto(out *DeviceDriverSpec) {
 	*out = *in
@@ -137,6 +268,48 @@ func (in *DeviceDriverSpec) DeepCopyInto(out *DeviceDriverSpec) {
 		*out = new(corev1.Container)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Debug != nil {
+		in, out := &in.Debug, &out.Debug
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sharding != nil {
+		in, out := &in.Sharding, &out.Sharding
+		*out = new(ShardingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]rbacv1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDriverSpec.
@@ -149,6 +322,47 @@ func (in *DeviceDriverSpec) DeepCopy() *DeviceDriverSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDriverStatus) DeepCopyInto(out *DeviceDriverStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.BoundNetworkNodes != nil {
+		in, out := &in.BoundNetworkNodes, &out.BoundNetworkNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailedNetworkNodes != nil {
+		in, out := &in.FailedNetworkNodes, &out.FailedNetworkNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDriverStatus.
+func (in *DeviceDriverStatus) DeepCopy() *DeviceDriverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDriverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDriverTargetConfig) DeepCopyInto(out *DeviceDriverTargetConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDriverTargetConfig.
+func (in *DeviceDriverTargetConfig) DeepCopy() *DeviceDriverTargetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDriverTargetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceStatus) DeepCopyInto(out *DeviceStatus) {
 	*out = *in
@@ -206,6 +420,104 @@ func (in *NetworkNode) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkNodeClass) DeepCopyInto(out *NetworkNodeClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNodeClass.
+func (in *NetworkNodeClass) DeepCopy() *NetworkNodeClass {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkNodeClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkNodeClass) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkNodeClassList) DeepCopyInto(out *NetworkNodeClassList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkNodeClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNodeClassList.
+func (in *NetworkNodeClassList) DeepCopy() *NetworkNodeClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkNodeClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkNodeClassList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkNodeClassSpec) DeepCopyInto(out *NetworkNodeClassSpec) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(TargetDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeviceDriverKind != nil {
+		in, out := &in.DeviceDriverKind, &out.DeviceDriverKind
+		*out = new(DeviceDriverKind)
+		**out = **in
+	}
+	if in.GrpcServerPort != nil {
+		in, out := &in.GrpcServerPort, &out.GrpcServerPort
+		*out = new(int)
+		**out = **in
+	}
+	if in.DeviceDriverReference != nil {
+		in, out := &in.DeviceDriverReference, &out.DeviceDriverReference
+		*out = new(DeviceDriverReference)
+		**out = **in
+	}
+	if in.DeviceDriverSelector != nil {
+		in, out := &in.DeviceDriverSelector, &out.DeviceDriverSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNodeClassSpec.
+func (in *NetworkNodeClassSpec) DeepCopy() *NetworkNodeClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkNodeClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkNodeList) DeepCopyInto(out *NetworkNodeList) {
 	*out = *in
@@ -246,6 +558,11 @@ func (in *NetworkNodeSpec) DeepCopyInto(out *NetworkNodeSpec) {
 		*out = new(TargetDetails)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NetworkNodeClassName != nil {
+		in, out := &in.NetworkNodeClassName, &out.NetworkNodeClassName
+		*out = new(string)
+		**out = **in
+	}
 	if in.DeviceDriverKind != nil {
 		in, out := &in.DeviceDriverKind, &out.DeviceDriverKind
 		*out = new(DeviceDriverKind)
@@ -256,6 +573,21 @@ func (in *NetworkNodeSpec) DeepCopyInto(out *NetworkNodeSpec) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeviceDriverReference != nil {
+		in, out := &in.DeviceDriverReference, &out.DeviceDriverReference
+		*out = new(DeviceDriverReference)
+		**out = **in
+	}
+	if in.DeviceDriverSelector != nil {
+		in, out := &in.DeviceDriverSelector, &out.DeviceDriverSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNodeSpec.
@@ -274,6 +606,26 @@ func (in *NetworkNodeStatus) DeepCopyInto(out *NetworkNodeStatus) {
 	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
 	out.ControllerRef = in.ControllerRef
 	in.DeviceStatus.DeepCopyInto(&out.DeviceStatus)
+	if in.DeviceDriverReference != nil {
+		in, out := &in.DeviceDriverReference, &out.DeviceDriverReference
+		*out = new(DeviceDriverReference)
+		**out = **in
+	}
+	if in.UsedBy != nil {
+		in, out := &in.UsedBy, &out.UsedBy
+		*out = make([]commonv1.TypedReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(DeviceDriverEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EffectiveNetworkNodeSpec != nil {
+		in, out := &in.EffectiveNetworkNodeSpec, &out.EffectiveNetworkNodeSpec
+		*out = new(NetworkNodeSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNodeStatus.
@@ -344,6 +696,106 @@ func (in *NetworkNodeUsageList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.CanarySelector != nil {
+		in, out := &in.CanarySelector, &out.CanarySelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShardingSpec) DeepCopyInto(out *ShardingSpec) {
+	*out = *in
+	if in.GrpcServerPort != nil {
+		in, out := &in.GrpcServerPort, &out.GrpcServerPort
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShardingSpec.
+func (in *ShardingSpec) DeepCopy() *ShardingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ShardingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetDefaults) DeepCopyInto(out *TargetDefaults) {
+	*out = *in
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(string)
+		**out = **in
+	}
+	if in.ProxyCredentialsName != nil {
+		in, out := &in.ProxyCredentialsName, &out.ProxyCredentialsName
+		*out = new(string)
+		**out = **in
+	}
+	if in.CredentialsName != nil {
+		in, out := &in.CredentialsName, &out.CredentialsName
+		*out = new(string)
+		**out = **in
+	}
+	if in.TLSCredentialsName != nil {
+		in, out := &in.TLSCredentialsName, &out.TLSCredentialsName
+		*out = new(string)
+		**out = **in
+	}
+	if in.SkipVerify != nil {
+		in, out := &in.SkipVerify, &out.SkipVerify
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Insecure != nil {
+		in, out := &in.Insecure, &out.Insecure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Encoding != nil {
+		in, out := &in.Encoding, &out.Encoding
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetDefaults.
+func (in *TargetDefaults) DeepCopy() *TargetDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetDetails) DeepCopyInto(out *TargetDetails) {
 	*out = *in
@@ -357,6 +809,11 @@ func (in *TargetDetails) DeepCopyInto(out *TargetDetails) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ProxyCredentialsName != nil {
+		in, out := &in.ProxyCredentialsName, &out.ProxyCredentialsName
+		*out = new(string)
+		**out = **in
+	}
 	if in.CredentialsName != nil {
 		in, out := &in.CredentialsName, &out.CredentialsName
 		*out = new(string)
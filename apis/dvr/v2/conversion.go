@@ -0,0 +1,219 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+)
+
+// The v2 API groups the device driver kind, the device driver reference and
+// selector of a network node in its device driver and the grpc server port in
+// its grpc server. v1 has no representation for a device driver or grpc
+// server without any field set, so the v2 schema rejects them, which keeps
+// the conversion lossless in both directions.
+
+// ConvertTo converts the NetworkNode to the NetworkNode of v1.
+func (nn *NetworkNode) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*ndddvrv1.NetworkNode)
+	dst.ObjectMeta = nn.ObjectMeta
+	dst.Spec = *convertNetworkNodeSpecTo(&nn.Spec)
+	dst.Status = ndddvrv1.NetworkNodeStatus{
+		ConditionedStatus:     nn.Status.ConditionedStatus,
+		ControllerRef:         nn.Status.ControllerRef,
+		DeviceStatus:          convertDeviceStatusTo(nn.Status.DeviceStatus),
+		DeviceDriverReference: (*ndddvrv1.DeviceDriverReference)(nn.Status.DeviceDriverReference),
+		Users:                 nn.Status.Users,
+		UsedBy:                nn.Status.UsedBy,
+		Endpoint:              convertEndpointTo(nn.Status.Endpoint),
+
+		EffectiveNetworkNodeSpec: convertNetworkNodeSpecTo(nn.Status.EffectiveNetworkNodeSpec),
+	}
+	return nil
+}
+
+// ConvertFrom converts the NetworkNode of v1 to the NetworkNode.
+func (nn *NetworkNode) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*ndddvrv1.NetworkNode)
+	nn.ObjectMeta = src.ObjectMeta
+	nn.Spec = *convertNetworkNodeSpecFrom(&src.Spec)
+	nn.Status = NetworkNodeStatus{
+		ConditionedStatus:     src.Status.ConditionedStatus,
+		ControllerRef:         src.Status.ControllerRef,
+		DeviceStatus:          convertDeviceStatusFrom(src.Status.DeviceStatus),
+		DeviceDriverReference: (*DeviceDriverReference)(src.Status.DeviceDriverReference),
+		Users:                 src.Status.Users,
+		UsedBy:                src.Status.UsedBy,
+		Endpoint:              convertEndpointFrom(src.Status.Endpoint),
+
+		EffectiveNetworkNodeSpec: convertNetworkNodeSpecFrom(src.Status.EffectiveNetworkNodeSpec),
+	}
+	return nil
+}
+
+// ConvertTo converts the DeviceDriver to the DeviceDriver of v1.
+func (dd *DeviceDriver) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*ndddvrv1.DeviceDriver)
+	dst.ObjectMeta = dd.ObjectMeta
+	dst.Spec = *convertDeviceDriverSpecTo(&dd.Spec)
+	dst.Status = ndddvrv1.DeviceDriverStatus(dd.Status)
+	return nil
+}
+
+// ConvertFrom converts the DeviceDriver of v1 to the DeviceDriver.
+func (dd *DeviceDriver) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*ndddvrv1.DeviceDriver)
+	dd.ObjectMeta = src.ObjectMeta
+	dd.Spec = *convertDeviceDriverSpecFrom(&src.Spec)
+	dd.Status = DeviceDriverStatus(src.Status)
+	return nil
+}
+
+func convertNetworkNodeSpecTo(in *NetworkNodeSpec) *ndddvrv1.NetworkNodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := &ndddvrv1.NetworkNodeSpec{
+		Target:               (*ndddvrv1.TargetDetails)(in.Target),
+		NetworkNodeClassName: in.NetworkNodeClassName,
+		Maintenance:          in.Maintenance,
+	}
+	if d := in.DeviceDriver; d != nil {
+		out.DeviceDriverKind = (*ndddvrv1.DeviceDriverKind)(d.Kind)
+		out.DeviceDriverReference = (*ndddvrv1.DeviceDriverReference)(d.Reference)
+		out.DeviceDriverSelector = d.Selector
+	}
+	if g := in.GrpcServer; g != nil {
+		out.GrpcServerPort = g.Port
+	}
+	return out
+}
+
+func convertNetworkNodeSpecFrom(in *ndddvrv1.NetworkNodeSpec) *NetworkNodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := &NetworkNodeSpec{
+		Target:               (*TargetDetails)(in.Target),
+		NetworkNodeClassName: in.NetworkNodeClassName,
+		Maintenance:          in.Maintenance,
+	}
+	if in.DeviceDriverKind != nil || in.DeviceDriverReference != nil || in.DeviceDriverSelector != nil {
+		out.DeviceDriver = &DeviceDriverDetails{
+			Kind:      (*DeviceDriverKind)(in.DeviceDriverKind),
+			Reference: (*DeviceDriverReference)(in.DeviceDriverReference),
+			Selector:  in.DeviceDriverSelector,
+		}
+	}
+	if in.GrpcServerPort != nil {
+		out.GrpcServer = &GrpcServerDetails{Port: in.GrpcServerPort}
+	}
+	return out
+}
+
+func convertDeviceDriverSpecTo(in *DeviceDriverSpec) *ndddvrv1.DeviceDriverSpec {
+	if in == nil {
+		return nil
+	}
+	out := &ndddvrv1.DeviceDriverSpec{
+		Container:          in.Container,
+		Debug:              in.Debug,
+		PodTemplate:        in.PodTemplate,
+		Rollout:            (*ndddvrv1.RolloutStrategy)(in.Rollout),
+		Permissions:        in.Permissions,
+		PodSecurityContext: in.PodSecurityContext,
+		SecurityContext:    in.SecurityContext,
+		NetworkPolicy:      in.NetworkPolicy,
+	}
+	if s := in.Sharding; s != nil {
+		out.Sharding = &ndddvrv1.ShardingSpec{TargetsPerShard: s.TargetsPerShard}
+		if s.GrpcServer != nil {
+			out.Sharding.GrpcServerPort = s.GrpcServer.Port
+		}
+	}
+	return out
+}
+
+func convertDeviceDriverSpecFrom(in *ndddvrv1.DeviceDriverSpec) *DeviceDriverSpec {
+	if in == nil {
+		return nil
+	}
+	out := &DeviceDriverSpec{
+		Container:          in.Container,
+		Debug:              in.Debug,
+		PodTemplate:        in.PodTemplate,
+		Rollout:            (*RolloutStrategy)(in.Rollout),
+		Permissions:        in.Permissions,
+		PodSecurityContext: in.PodSecurityContext,
+		SecurityContext:    in.SecurityContext,
+		NetworkPolicy:      in.NetworkPolicy,
+	}
+	if s := in.Sharding; s != nil {
+		out.Sharding = &ShardingSpec{TargetsPerShard: s.TargetsPerShard}
+		if s.GrpcServerPort != nil {
+			out.Sharding.GrpcServer = &GrpcServerDetails{Port: s.GrpcServerPort}
+		}
+	}
+	return out
+}
+
+func convertDeviceStatusTo(in DeviceStatus) ndddvrv1.DeviceStatus {
+	return ndddvrv1.DeviceStatus{
+		DeviceDetails:        (*ndddvrv1.DeviceDetails)(in.DeviceDetails),
+		UsedNetworkNodeSpec:  convertNetworkNodeSpecTo(in.UsedNetworkNodeSpec),
+		UsedDeviceDriverSpec: convertDeviceDriverSpecTo(in.UsedDeviceDriverSpec),
+		EffectiveSpecHash:    in.EffectiveSpecHash,
+		ObservedGeneration:   in.ObservedGeneration,
+	}
+}
+
+func convertDeviceStatusFrom(in ndddvrv1.DeviceStatus) DeviceStatus {
+	return DeviceStatus{
+		DeviceDetails:        (*DeviceDetails)(in.DeviceDetails),
+		UsedNetworkNodeSpec:  convertNetworkNodeSpecFrom(in.UsedNetworkNodeSpec),
+		UsedDeviceDriverSpec: convertDeviceDriverSpecFrom(in.UsedDeviceDriverSpec),
+		EffectiveSpecHash:    in.EffectiveSpecHash,
+		ObservedGeneration:   in.ObservedGeneration,
+	}
+}
+
+func convertEndpointTo(in *DeviceDriverEndpoint) *ndddvrv1.DeviceDriverEndpoint {
+	if in == nil {
+		return nil
+	}
+	return &ndddvrv1.DeviceDriverEndpoint{
+		Host:       in.Host,
+		Port:       in.Port,
+		ServerName: in.ServerName,
+		CA:         (*ndddvrv1.CAReference)(in.CA),
+		Ready:      in.Ready,
+	}
+}
+
+func convertEndpointFrom(in *ndddvrv1.DeviceDriverEndpoint) *DeviceDriverEndpoint {
+	if in == nil {
+		return nil
+	}
+	return &DeviceDriverEndpoint{
+		Host:       in.Host,
+		Port:       in.Port,
+		ServerName: in.ServerName,
+		CA:         (*CAReference)(in.CA),
+		Ready:      in.Ready,
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+)
+
+const fuzzIterations = 1000
+
+// newFuzzer returns a fuzzer for the v1 and v2 network nodes and device
+// drivers. The v2 schema rejects a device driver or grpc server without any
+// field set, so the fuzzer only creates them with at least one field set.
+func newFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.NewWithSeed(seed).NilChance(0.3).NumElements(0, 2).MaxDepth(8).Funcs(
+		func(d *DeviceDriverDetails, c fuzz.Continue) {
+			c.FuzzNoCustom(d)
+			if d.Kind == nil && d.Reference == nil && d.Selector == nil {
+				k := DeviceDriverKindGnmi
+				d.Kind = &k
+			}
+		},
+		func(g *GrpcServerDetails, c fuzz.Continue) {
+			c.FuzzNoCustom(g)
+			if g.Port == nil {
+				p := c.Int()
+				g.Port = &p
+			}
+		},
+	)
+}
+
+func TestNetworkNodeRoundTrip(t *testing.T) {
+	f := newFuzzer(1)
+
+	t.Run("V1ToV2ToV1", func(t *testing.T) {
+		for i := 0; i < fuzzIterations; i++ {
+			want := &ndddvrv1.NetworkNode{}
+			f.Fuzz(want)
+			// the type meta is set by the api server, not by the conversion
+			want.TypeMeta = metav1.TypeMeta{}
+
+			nn := &NetworkNode{}
+			if err := nn.ConvertFrom(want.DeepCopy()); err != nil {
+				t.Fatalf("ConvertFrom(...): %v", err)
+			}
+			got := &ndddvrv1.NetworkNode{}
+			if err := nn.ConvertTo(got); err != nil {
+				t.Fatalf("ConvertTo(...): %v", err)
+			}
+			if !equality.Semantic.DeepEqual(want, got) {
+				t.Fatalf("round trip v1 -> v2 -> v1: -want, +got:\n%s", diff.ObjectReflectDiff(want, got))
+			}
+		}
+	})
+
+	t.Run("V2ToV1ToV2", func(t *testing.T) {
+		for i := 0; i < fuzzIterations; i++ {
+			want := &NetworkNode{}
+			f.Fuzz(want)
+			// the type meta is set by the api server, not by the conversion
+			want.TypeMeta = metav1.TypeMeta{}
+
+			hub := &ndddvrv1.NetworkNode{}
+			if err := want.DeepCopy().ConvertTo(hub); err != nil {
+				t.Fatalf("ConvertTo(...): %v", err)
+			}
+			got := &NetworkNode{}
+			if err := got.ConvertFrom(hub); err != nil {
+				t.Fatalf("ConvertFrom(...): %v", err)
+			}
+			if !equality.Semantic.DeepEqual(want, got) {
+				t.Fatalf("round trip v2 -> v1 -> v2: -want, +got:\n%s", diff.ObjectReflectDiff(want, got))
+			}
+		}
+	})
+}
+
+func TestDeviceDriverRoundTrip(t *testing.T) {
+	f := newFuzzer(2)
+
+	t.Run("V1ToV2ToV1", func(t *testing.T) {
+		for i := 0; i < fuzzIterations; i++ {
+			want := &ndddvrv1.DeviceDriver{}
+			f.Fuzz(want)
+			// the type meta is set by the api server, not by the conversion
+			want.TypeMeta = metav1.TypeMeta{}
+
+			dd := &DeviceDriver{}
+			if err := dd.ConvertFrom(want.DeepCopy()); err != nil {
+				t.Fatalf("ConvertFrom(...): %v", err)
+			}
+			got := &ndddvrv1.DeviceDriver{}
+			if err := dd.ConvertTo(got); err != nil {
+				t.Fatalf("ConvertTo(...): %v", err)
+			}
+			if !equality.Semantic.DeepEqual(want, got) {
+				t.Fatalf("round trip v1 -> v2 -> v1: -want, +got:\n%s", diff.ObjectReflectDiff(want, got))
+			}
+		}
+	})
+
+	t.Run("V2ToV1ToV2", func(t *testing.T) {
+		for i := 0; i < fuzzIterations; i++ {
+			want := &DeviceDriver{}
+			f.Fuzz(want)
+			// the type meta is set by the api server, not by the conversion
+			want.TypeMeta = metav1.TypeMeta{}
+
+			hub := &ndddvrv1.DeviceDriver{}
+			if err := want.DeepCopy().ConvertTo(hub); err != nil {
+				t.Fatalf("ConvertTo(...): %v", err)
+			}
+			got := &DeviceDriver{}
+			if err := got.ConvertFrom(hub); err != nil {
+				t.Fatalf("ConvertFrom(...): %v", err)
+			}
+			if !equality.Semantic.DeepEqual(want, got) {
+				t.Fatalf("round trip v2 -> v1 -> v2: -want, +got:\n%s", diff.ObjectReflectDiff(want, got))
+			}
+		}
+	})
+}
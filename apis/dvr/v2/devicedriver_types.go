@@ -0,0 +1,219 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// DeviceDriverSpec defines the desired state of DeviceDriver
+type DeviceDriverSpec struct {
+	// Container defines the container parameters for the device driver.
+	// The args and the env values of the device driver container are templates
+	// which are rendered per network node and appended to the mandatory args
+	// and env of ndd. The templates can use the following placeholders:
+	// {{.NetworkNodeName}}, {{.Namespace}}, {{.GrpcServerPort}},
+	// {{.TargetAddress}} and {{.Encoding}}
+	Container *corev1.Container `json:"container,omitempty"`
+
+	// Debug enables the debug logging of the device driver
+	// +optional
+	Debug *bool `json:"debug,omitempty"`
+
+	// PodTemplate defines the pod of the device driver. The device driver
+	// container is the Container when specified, otherwise the first container
+	// of the pod template; the other containers are deployed as sidecars.
+	// ndd owns the service account, the ndd labels and annotations, the
+	// config, credentials and tls volumes and their mounts in the device driver
+	// container, and the mandatory args and env of the device driver container.
+	// All other fields, e.g. sidecars, volumes, nodeSelector, tolerations,
+	// affinity, imagePullSecrets and priorityClassName, are owned by the user.
+	// +optional
+	PodTemplate *corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
+
+	// Rollout defines how a change of the device driver is rolled out to the
+	// network nodes using the device driver. When not specified the change is
+	// rolled out to all network nodes at once.
+	// +optional
+	Rollout *RolloutStrategy `json:"rollout,omitempty"`
+
+	// Sharding serves multiple network nodes from one device driver deployment,
+	// a shard. The network nodes using the device driver are assigned to the
+	// shards, every network node keeps its own service selecting the pods of
+	// its shard. The rollout strategy does not apply to a sharded device driver,
+	// its shards are rolled out at once.
+	// +optional
+	Sharding *ShardingSpec `json:"sharding,omitempty"`
+
+	// Permissions are the RBAC rules the device driver needs in addition to the
+	// access to its network node, configmap, secrets and events. The
+	// permissions are validated against the permissions device drivers may
+	// request, a device driver requesting disallowed permissions is not
+	// deployed.
+	// +optional
+	Permissions []rbacv1.PolicyRule `json:"permissions,omitempty"`
+
+	// PodSecurityContext overrides the security context of the device driver
+	// pod. By default the device driver pod runs as non root with the
+	// RuntimeDefault seccomp profile.
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// SecurityContext overrides the security context of the device driver
+	// container. By default the containers of the device driver run as non root
+	// with a read-only root filesystem, without privilege escalation, without
+	// capabilities and with the RuntimeDefault seccomp profile.
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// NetworkPolicy isolates the device driver pods with a network policy,
+	// which only allows ingress to the grpc server of the device driver from
+	// the providers and the core, and egress to the target, the proxy, DNS and
+	// the kubernetes API server. When not specified the setting of the core
+	// applies.
+	// +optional
+	NetworkPolicy *bool `json:"networkPolicy,omitempty"`
+}
+
+// ShardingSpec defines how the network nodes using a device driver are
+// distributed over the shards of the device driver
+type ShardingSpec struct {
+	// TargetsPerShard is the maximum number of network nodes served by a shard
+	// +kubebuilder:validation:Minimum=1
+	TargetsPerShard int `json:"targetsPerShard"`
+
+	// GrpcServer defines the grpc server of the shards, the services of the
+	// network nodes forward their grpc server port to it
+	// +optional
+	GrpcServer *GrpcServerDetails `json:"grpcServer,omitempty"`
+}
+
+// RolloutStrategy defines how a change of the device driver is rolled out to
+// the network nodes using the device driver
+type RolloutStrategy struct {
+	// MaxUnavailable is the maximum number of network nodes, or the percentage
+	// of the network nodes, using the device driver that can be unavailable
+	// during the rollout. The network nodes that are unhealthy before they are
+	// rolled out are rolled out first and do not count against it.
+	// +optional
+	// +kubebuilder:default=1
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// CanarySelector selects the canary network nodes by their labels. The
+	// canary network nodes are rolled out one at a time in the order of their
+	// names and all of them have to be healthy before the other network nodes
+	// are rolled out.
+	// +optional
+	CanarySelector *metav1.LabelSelector `json:"canarySelector,omitempty"`
+
+	// Paused pauses the rollout, the network nodes that are rolled out are
+	// kept, no further network nodes are rolled out until the rollout is
+	// resumed
+	// +optional
+	Paused *bool `json:"paused,omitempty"`
+}
+
+// DeviceDriverStatus defines the observed state of DeviceDriver
+type DeviceDriverStatus struct {
+	nddv1.ConditionedStatus `json:",inline"`
+
+	// ObservedGeneration is the generation of the device driver of which the
+	// rollout is reported
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Revision is the revision of the device driver that is rolled out
+	Revision string `json:"revision,omitempty"`
+
+	// Image is the image of the device driver container of the revision
+	Image string `json:"image,omitempty"`
+
+	// BoundNetworkNodes are the network nodes bound to the device driver
+	BoundNetworkNodes []string `json:"boundNetworkNodes,omitempty"`
+
+	// NetworkNodes is the number of network nodes using the device driver
+	NetworkNodes int `json:"networkNodes,omitempty"`
+
+	// UpdatedNetworkNodes is the number of network nodes running the revision
+	UpdatedNetworkNodes int `json:"updatedNetworkNodes,omitempty"`
+
+	// OutdatedNetworkNodes is the number of network nodes running a previous
+	// revision of the device driver
+	OutdatedNetworkNodes int `json:"outdatedNetworkNodes,omitempty"`
+
+	// HealthyNetworkNodes is the number of network nodes of which the device
+	// driver is healthy
+	HealthyNetworkNodes int `json:"healthyNetworkNodes,omitempty"`
+
+	// UnavailableNetworkNodes is the number of network nodes of which the
+	// device driver is not healthy or is being rolled out
+	UnavailableNetworkNodes int `json:"unavailableNetworkNodes,omitempty"`
+
+	// Shards is the number of shards of a sharded device driver
+	Shards int `json:"shards,omitempty"`
+
+	// FailedNetworkNodes are the network nodes running the revision of which
+	// the device driver failed to become healthy
+	FailedNetworkNodes []string `json:"failedNetworkNodes,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DeviceDriver is the Schema for the devicedrivers API
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="VALID",type="string",JSONPath=".status.conditions[?(@.kind=='Valid')].status"
+// +kubebuilder:printcolumn:name="ROLLOUT",type="string",JSONPath=".status.conditions[?(@.kind=='Rollout')].reason"
+// +kubebuilder:printcolumn:name="NODES",type="integer",JSONPath=".status.networkNodes"
+// +kubebuilder:printcolumn:name="UPDATED",type="integer",JSONPath=".status.updatedNetworkNodes"
+// +kubebuilder:printcolumn:name="OUTDATED",type="integer",JSONPath=".status.outdatedNetworkNodes"
+// +kubebuilder:printcolumn:name="HEALTHY",type="integer",JSONPath=".status.healthyNetworkNodes"
+// +kubebuilder:printcolumn:name="UNAVAILABLE",type="integer",JSONPath=".status.unavailableNetworkNodes"
+// +kubebuilder:printcolumn:name="IMAGE",type="string",JSONPath=".status.image",priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+type DeviceDriver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeviceDriverSpec   `json:"spec,omitempty"`
+	Status DeviceDriverStatus `json:"status,omitempty"`
+}
+
+// GetCondition of this DeviceDriver.
+func (dd *DeviceDriver) GetCondition(ct nddv1.ConditionKind) nddv1.Condition {
+	return dd.Status.GetCondition(ct)
+}
+
+// SetConditions of this DeviceDriver.
+func (dd *DeviceDriver) SetConditions(c ...nddv1.Condition) {
+	dd.Status.SetConditions(c...)
+}
+
+//+kubebuilder:object:root=true
+
+// DeviceDriverList contains a list of DeviceDriver
+type DeviceDriverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeviceDriver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DeviceDriver{}, &DeviceDriverList{})
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NetworkNodeSpec defines the desired state of NetworkNode. The fields of a
+// network node without network node class get the built-in defaults from the
+// mutating webhook, the fields of a network node with network node class are
+// left unset and default to the class. Clients read the defaulted fields from
+// the effective network node spec in the status, not from the spec.
+type NetworkNodeSpec struct {
+	// Target defines the details how we connect to the network device
+	Target *TargetDetails `json:"target"`
+
+	// NetworkNodeClassName is the name of the network node class providing the
+	// defaults of the fields the network node does not specify
+	// +optional
+	NetworkNodeClassName *string `json:"networkNodeClassName,omitempty"`
+
+	// DeviceDriver defines the device driver details to connect to the network device
+	// +optional
+	DeviceDriver *DeviceDriverDetails `json:"deviceDriver,omitempty"`
+
+	// GrpcServer defines the grpc server to connect to the device driver from
+	// the network device provider
+	// +optional
+	GrpcServer *GrpcServerDetails `json:"grpcServer,omitempty"`
+
+	// Maintenance takes the network node out of management, e.g. during an RMA
+	// or a maintenance window. The device driver is scaled to zero while its
+	// configuration and the status of the network node are kept, a network
+	// node served by a shard is taken out of its shard.
+	// +optional
+	Maintenance *bool `json:"maintenance,omitempty"`
+}
+
+// NetworkNodeStatus defines the observed state of NetworkNode
+type NetworkNodeStatus struct {
+	nddv1.ConditionedStatus `json:",inline"`
+	ControllerRef           nddv1.Reference `json:"controllerRef,omitempty"`
+	DeviceStatus            `json:",inline"`
+
+	// DeviceDriverReference references the device driver the network node is
+	// bound to, it is empty when the built-in device driver is used
+	DeviceDriverReference *DeviceDriverReference `json:"deviceDriverRef,omitempty"`
+
+	// Users is the number of network node usages referencing the network node
+	Users int64 `json:"users,omitempty"`
+
+	// UsedBy references the resources using the network node
+	UsedBy []nddv1.TypedReference `json:"usedBy,omitempty"`
+
+	// Endpoint is the endpoint of the device driver serving the network node,
+	// it is empty when no device driver is deployed for the network node
+	Endpoint *DeviceDriverEndpoint `json:"endpoint,omitempty"`
+
+	// EffectiveNetworkNodeSpec is the spec of the network node merged with the
+	// defaults of its network node class and the built-in defaults
+	EffectiveNetworkNodeSpec *NetworkNodeSpec `json:"effectiveNetworkNodeSpec,omitempty"`
+}
+
+// DeviceDriverEndpoint is the endpoint of the grpc server of the device driver
+// serving the network node, through which the providers reach the network node.
+type DeviceDriverEndpoint struct {
+	// Host is the dns name of the device driver
+	Host string `json:"host"`
+
+	// Port is the port of the grpc server of the device driver
+	Port int `json:"port"`
+
+	// ServerName is the name to verify the serving certificate of the device
+	// driver with, it is empty when the device driver serves plain grpc
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// CA references the certificate of the certificate authority that issued
+	// the serving certificate of the device driver
+	// +optional
+	CA *CAReference `json:"ca,omitempty"`
+
+	// Ready is true when the device driver serving the network node is healthy
+	Ready bool `json:"ready"`
+}
+
+// CAReference references the key of a configmap holding the certificate of a
+// certificate authority.
+type CAReference struct {
+	// Namespace of the configmap
+	Namespace string `json:"namespace"`
+
+	// Name of the configmap
+	Name string `json:"name"`
+
+	// Key of the certificate in the configmap
+	Key string `json:"key"`
+}
+
+// +kubebuilder:object:root=true
+
+// NetworkNode is the Schema for the networknodes API
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="HEALTHY",type="string",JSONPath=".status.conditions[?(@.kind=='DeviceDriverHealthy')].status"
+// +kubebuilder:printcolumn:name="CONFIGURED",type="string",JSONPath=".status.conditions[?(@.kind=='DeviceDriverConfigured')].status"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.kind=='DeviceDriverReady')].status"
+// +kubebuilder:printcolumn:name="ADDRESS",type="string",JSONPath=".spec.target.address",description="address to connect to the device'"
+// +kubebuilder:printcolumn:name="CONN-KIND",type="string",JSONPath=".status.effectiveNetworkNodeSpec.deviceDriver.kind",description="Kind of communication type to the device"
+// +kubebuilder:printcolumn:name="CLASS",type="string",JSONPath=".spec.networkNodeClassName",description="network node class of the network node",priority=1
+// +kubebuilder:printcolumn:name="DEVICEDRIVER",type="string",JSONPath=".status.deviceDriverRef.name",description="device driver the network node is bound to",priority=1
+// +kubebuilder:printcolumn:name="ENDPOINT",type="string",JSONPath=".status.endpoint.host",description="endpoint of the device driver serving the network node",priority=1
+// +kubebuilder:printcolumn:name="USERS",type="integer",JSONPath=".status.users",description="number of resources using the network node",priority=1
+// +kubebuilder:printcolumn:name="TYPE",type="string",JSONPath=".status.deviceDetails.type",description="Type of device"
+// +kubebuilder:printcolumn:name="KIND",type="string",JSONPath=".status.deviceDetails.kind",description="Kind of device"
+// +kubebuilder:printcolumn:name="SWVERSION",type="string",JSONPath=".status.deviceDetails.swVersion",description="SW version of the device"
+// +kubebuilder:printcolumn:name="MACADDRESS",type="string",JSONPath=".status.deviceDetails.macAddress",description="macAddress of the device"
+// +kubebuilder:printcolumn:name="SERIALNBR",type="string",JSONPath=".status.deviceDetails.serialNumber",description="serialNumber of the device"
+// +kubebuilder:printcolumn:name="GRPCSERVERPORT",type="string",JSONPath=".status.effectiveNetworkNodeSpec.grpcServer.port",description="grpc server port to connect to the devic driver"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={ndd,dvr},shortName=nn
+type NetworkNode struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NetworkNodeSpec   `json:"spec,omitempty"`
+	Status NetworkNodeStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NetworkNodeList contains a list of NetworkNode
+type NetworkNodeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NetworkNode `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NetworkNode{}, &NetworkNodeList{})
+}
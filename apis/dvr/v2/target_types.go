@@ -0,0 +1,164 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeviceDriverKind represents the kinds of device drivers are supported
+// by the network device driver
+type DeviceDriverKind string
+
+const (
+	// DeviceDriverKindGnmi operates using the gnmi specification
+	DeviceDriverKindGnmi DeviceDriverKind = "gnmi"
+
+	// DeviceDriverKindNetconf operates using the netconf specification
+	DeviceDriverKindNetconf DeviceDriverKind = "netconf"
+)
+
+// TargetDetails contains the information necessary to communicate with
+// the network node.
+type TargetDetails struct {
+	// Address holds the IP:port for accessing the network node
+	// +kubebuilder:validation:Required
+	Address *string `json:"address"`
+
+	// Proxy used to communicate to the target network node, as a URL with
+	// scheme socks5, socks5h or http (HTTP CONNECT), e.g. socks5://jump:1080
+	// +kubebuilder:validation:Optional
+	Proxy *string `json:"proxy,omitempty"`
+
+	// The name of the secret containing the credentials of the proxy (requires
+	// keys "username" and "password").
+	// +kubebuilder:validation:Optional
+	ProxyCredentialsName *string `json:"proxyCredentialsName,omitempty"`
+
+	// The name of the secret containing the credentials (requires
+	// keys "username" and "password"), defaults to the credentials of the
+	// network node class.
+	// +kubebuilder:validation:Optional
+	CredentialsName *string `json:"credentialsName,omitempty"`
+
+	// The name of the secret containing the credentials (requires
+	// keys "TLSCA" and "TLSCert", " TLSKey").
+	// +kubebuilder:validation:Optional
+	TLSCredentialsName *string `json:"tlsCredentialsName,omitempty"`
+
+	// SkipVerify disables verification of server certificates when using
+	// HTTPS to connect to the Target. This is required when the server
+	// certificate is self-signed, but is insecure because it allows a
+	// man-in-the-middle to intercept the connection.
+	// +kubebuilder:validation:Optional
+	SkipVerify *bool `json:"skipVerify,omitempty"`
+
+	// Insecure runs the communication in an insecure manner
+	// +kubebuilder:validation:Optional
+	Insecure *bool `json:"insecure,omitempty"`
+
+	// Encoding defines the gnmi encoding, defaults to the encoding of the
+	// network node class or JSON_IETF
+	// +kubebuilder:validation:Enum=`JSON`;`BYTES`;`PROTO`;`ASCII`;`JSON_IETF`
+	// +kubebuilder:validation:Optional
+	Encoding *string `json:"encoding,omitempty"`
+}
+
+// DeviceDriverDetails defines the device driver of the network node
+// +kubebuilder:validation:MinProperties=1
+type DeviceDriverDetails struct {
+	// Kind defines the device driver kind, defaults to the device driver kind
+	// of the network node class or gnmi
+	// +kubebuilder:validation:Optional
+	Kind *DeviceDriverKind `json:"kind,omitempty"`
+
+	// Reference references the device driver used for the network node. When
+	// not specified the device driver is selected with the following
+	// precedence: the device drivers of the device driver kind matching the
+	// Selector, the device driver of the device driver kind marked as default,
+	// the only device driver of the device driver kind and last the built-in
+	// device driver of the device driver kind.
+	// +kubebuilder:validation:Optional
+	Reference *DeviceDriverReference `json:"ref,omitempty"`
+
+	// Selector selects the device driver used for the network node by the
+	// labels of the device drivers of the device driver kind. The selector
+	// takes precedence over the device driver marked as default.
+	// +kubebuilder:validation:Optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// DeviceDriverReference references a device driver
+type DeviceDriverReference struct {
+	// Name of the device driver
+	Name string `json:"name"`
+
+	// Namespace of the device driver, defaults to the default namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GrpcServerDetails defines the grpc server of the device driver
+// +kubebuilder:validation:MinProperties=1
+type GrpcServerDetails struct {
+	// Port defines the port of the grpc server of the device driver
+	// +kubebuilder:default:=9999
+	// +kubebuilder:validation:Optional
+	Port *int `json:"port,omitempty"`
+}
+
+// DeviceDetails collects information about the deiscovered device
+type DeviceDetails struct {
+	// the Type of device the device driver is connected to
+	Type *nddv1.DeviceType `json:"type,omitempty"`
+
+	// Host name of the device the device driver is connected to
+	HostName *string `json:"hostname,omitempty"`
+
+	// the Kind of device the device driver is connected to
+	Kind *string `json:"kind,omitempty"`
+
+	// SW version that is running on the device
+	SwVersion *string `json:"swVersion,omitempty"`
+
+	// the Mac address of the device the device driver is connected to
+	MacAddress *string `json:"macAddress,omitempty"`
+
+	// the Serial Number of the device the device driver is connected to
+	SerialNumber *string `json:"serialNumber,omitempty"`
+}
+
+// DeviceStatus defines the observed state of the Device
+type DeviceStatus struct {
+	// The discovered DeviceDetails
+	DeviceDetails *DeviceDetails `json:"deviceDetails,omitempty"`
+
+	// UsedNetworkNodeSpec identifies the used networkNode spec when installed
+	UsedNetworkNodeSpec *NetworkNodeSpec `json:"usedNetworkNodeSpec,omitempty"`
+
+	// UsedDeviceDriverSpec identifies the used deviceDriver spec when installed
+	UsedDeviceDriverSpec *DeviceDriverSpec `json:"usedDeviceDriverSpec,omitempty"`
+
+	// EffectiveSpecHash is the hash of the effective device driver
+	// configuration that is rolled out
+	EffectiveSpecHash string `json:"effectiveSpecHash,omitempty"`
+
+	// ObservedGeneration is the generation of the network node of which the
+	// device driver configuration is rolled out
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
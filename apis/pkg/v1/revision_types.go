@@ -34,6 +34,24 @@ const (
 	PackageRevisionInactive PackageRevisionDesiredState = "Inactive"
 )
 
+const (
+	// SuffixClientCertSecret is the suffix of the name of the secret holding
+	// the client certificate of a package revision, which is issued by the core
+	// for mutual TLS with the device drivers.
+	SuffixClientCertSecret = "client-cert"
+
+	// ClientCertVolume is the name of the volume of the client certificate.
+	ClientCertVolume = "client-cert"
+
+	// ClientCertMountPath is the path of the client certificate in the pod of
+	// the packaged controller.
+	ClientCertMountPath = "/client-cert"
+
+	// AnnotationClientCertHash is the pod annotation holding the hash of the
+	// client certificate, which rolls the pod when the certificate is renewed.
+	AnnotationClientCertHash = "pkg.ndd.yndd.io/client-cert-hash"
+)
+
 // PackageRevisionSpec defines the desired state of Revision
 type PackageRevisionSpec struct {
 	// ControllerConfigRef references a ControllerConfig resource that will be
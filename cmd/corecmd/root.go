@@ -25,15 +25,18 @@ import (
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 
 	dvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	dvrv2 "github.com/netw-device-driver/ndd-core/apis/dvr/v2"
 	metapkgv1 "github.com/netw-device-driver/ndd-core/apis/pkg/meta/v1"
 	pkgv1 "github.com/netw-device-driver/ndd-core/apis/pkg/v1"
 	"github.com/netw-device-driver/ndd-core/internal/initializer"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	//+kubebuilder:scaffold:imports
@@ -66,12 +69,15 @@ func init() {
 
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(dvrv1.AddToScheme(scheme))
+	utilruntime.Must(dvrv2.AddToScheme(scheme))
 	utilruntime.Must(pkgv1.AddToScheme(scheme))
 	utilruntime.Must(metapkgv1.AddToScheme(scheme))
 	utilruntime.Must(extv1.AddToScheme(scheme))
 	utilruntime.Must(extv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 
+	zlog := zap.New(zap.JSONEncoder())
+
 	cfg, err := ctrl.GetConfig()
 	if err != nil {
 		fmt.Printf("cannot get config %s\n", err)
@@ -83,6 +89,7 @@ func init() {
 	}
 	i := initializer.New(cl,
 		initializer.NewLockObject(),
+		initializer.NewCertificateAuthority(os.Getenv("POD_NAMESPACE"), logging.NewLogrLogger(zlog.WithName("nddcoreinit"))),
 	)
 	if err := i.Init(context.TODO()); err != nil {
 		fmt.Printf("cannot initialize core %s\n", err)
@@ -31,6 +31,7 @@ import (
 	"github.com/netw-device-driver/ndd-core/internal/controllers/dvr"
 	"github.com/netw-device-driver/ndd-core/internal/controllers/pkg"
 	"github.com/netw-device-driver/ndd-core/internal/nddpkg"
+	"github.com/netw-device-driver/ndd-core/internal/webhook"
 	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
 	//+kubebuilder:scaffold:imports
 )
@@ -42,6 +43,9 @@ var (
 	concurrency          int
 	namespace            string
 	cacheDir             string
+	deviceDriverRole     string
+	networkPolicy        bool
+	webhookEnabled       bool
 )
 
 // startCmd represents the start command for the network device driver
@@ -78,10 +82,16 @@ var startCmd = &cobra.Command{
 			return errors.Wrap(err, "Cannot add ndd packages controllers to manager")
 		}
 
-		if err := dvr.Setup(mgr, logging.NewLogrLogger(zlog.WithName("nddcore-dvr")), namespace); err != nil {
+		if err := dvr.Setup(mgr, logging.NewLogrLogger(zlog.WithName("nddcore-dvr")), namespace, deviceDriverRole, networkPolicy); err != nil {
 			return errors.Wrap(err, "Cannot add ndd driver controllers to manager")
 		}
 
+		if webhookEnabled {
+			if err := webhook.Setup(mgr, logging.NewLogrLogger(zlog.WithName("nddcore-webhook")), namespace); err != nil {
+				return errors.Wrap(err, "Cannot add ndd webhooks to manager")
+			}
+		}
+
 		// +kubebuilder:scaffold:builder
 
 		if err := mgr.AddHealthzCheck("health", healthz.Ping); err != nil {
@@ -108,6 +118,9 @@ func init() {
 	startCmd.Flags().IntVarP(&concurrency, "concurrency", "", 1, "Number of items to process simultaneously")
 	startCmd.Flags().StringVarP(&namespace, "namespace", "n", os.Getenv("POD_NAMESPACE"), "Namespace used to unpack and run packages.")
 	startCmd.Flags().StringVarP(&cacheDir, "cache-dir", "c", "/cache", "Directory used for caching package images.")
+	startCmd.Flags().StringVarP(&deviceDriverRole, "device-driver-clusterrole", "", "", "A ClusterRole enumerating the permissions device drivers may request.")
+	startCmd.Flags().BoolVarP(&networkPolicy, "device-driver-network-policy", "", false, "Isolate the device driver pods with network policies, unless the device driver specifies otherwise.")
+	startCmd.Flags().BoolVarP(&webhookEnabled, "webhook-enabled", "", true, "Validate and default the ndd resources with admission webhooks.")
 
 }
 
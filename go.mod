@@ -11,10 +11,16 @@ require (
 	github.com/google/go-cmp v0.5.6
 	github.com/google/go-containerregistry v0.4.1
 	github.com/google/go-containerregistry/pkg/authn/k8schain v0.0.0-20210330174036-3259211c1f24
+	github.com/google/gofuzz v1.1.0
 	github.com/netw-device-driver/ndd-runtime v0.3.81
+	github.com/openconfig/gnmi v0.0.0-20210707145734-c69a5df04b53
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/afero v1.6.0
 	github.com/spf13/cobra v1.1.3
+	golang.org/x/crypto v0.0.0-20210220033148-5ea612d1eb83
+	golang.org/x/net v0.0.0-20210428140749-89ef3d95e781
+	google.golang.org/grpc v1.39.0
+	google.golang.org/protobuf v1.27.1
 	k8s.io/api v0.21.3
 	k8s.io/apiextensions-apiserver v0.21.2
 	k8s.io/apimachinery v0.21.3
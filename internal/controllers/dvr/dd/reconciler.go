@@ -0,0 +1,598 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dd
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/controllers/dvr/nn"
+	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
+	"github.com/netw-device-driver/ndd-runtime/pkg/event"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/meta"
+	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// Finalizer
+	finalizer = "devicedriver.dvr.ndd.yndd.io"
+
+	// Timers
+	reconcileTimeout = 1 * time.Minute
+	longWait         = 1 * time.Minute
+
+	// Errors
+	errGetDeviceDriver   = "cannot get device driver resource"
+	errListNetworkNodes  = "cannot list network nodes"
+	errCanarySelector    = "invalid canary selector"
+	errAdmitNetworkNode  = "cannot admit network node to the device driver revision"
+	errUpdateStatus      = "cannot update device driver status"
+	errRolloutHalted     = "rollout halted, network nodes failed to become healthy"
+	errMaxUnavailable    = "invalid max unavailable"
+	errListDeviceDrivers = "cannot list device drivers"
+	errAddFinalizer      = "cannot add device driver finalizer"
+	errRemoveFinalizer   = "cannot remove device driver finalizer"
+	errMissingKind       = "device driver has no device driver kind label, it can only be used by reference"
+	errConflictDefault   = "conflicting default device drivers of the device driver kind"
+	errAssignShard       = "cannot assign network node to the shard"
+	errDeployShard       = "cannot deploy shard"
+	errPruneShards       = "cannot prune shards"
+
+	// Event reasons
+	reasonRollout event.Reason = "RolloutDeviceDriver"
+	reasonSync    event.Reason = "SyncDeviceDriver"
+	reasonShard   event.Reason = "ShardDeviceDriver"
+)
+
+// ReconcilerOption is used to configure the Reconciler.
+type ReconcilerOption func(*Reconciler)
+
+// WithLogger specifies how the Reconciler should log messages.
+func WithLogger(log logging.Logger) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.log = log
+	}
+}
+
+// WithRecorder specifies how the Reconciler should record Kubernetes events.
+func WithRecorder(er event.Recorder) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.record = er
+	}
+}
+
+// WithShardHooks specifies how the Reconciler should deploy the shards of the
+// sharded device drivers.
+func WithShardHooks(h nn.ShardHooks) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.shards = h
+	}
+}
+
+// Reconciler reports the status of the device drivers and rolls them out to
+// the network nodes using them.
+type Reconciler struct {
+	client      client.Client
+	ddFinalizer resource.Finalizer
+	log         logging.Logger
+	record      event.Recorder
+	shards      nn.ShardHooks
+}
+
+// Setup adds a controller that reconciles the status and the rollout of the
+// device drivers.
+func Setup(mgr ctrl.Manager, l logging.Logger, namespace, allowClusterRole string, networkPolicy bool) error {
+	name := "dvr/devicedriver"
+
+	r := NewReconciler(mgr,
+		WithLogger(l.WithValues("controller", name)),
+		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		WithShardHooks(nn.NewDeviceDriverShardHooks(resource.ClientApplicator{
+			Client:     mgr.GetClient(),
+			Applicator: resource.NewAPIPatchingApplicator(mgr.GetClient()),
+		}, nn.NewNnValidator(resource.ClientApplicator{
+			Client:     mgr.GetClient(),
+			Applicator: resource.NewAPIPatchingApplicator(mgr.GetClient()),
+		}, nn.NewConfigMapKindRegistry(mgr.GetClient(), namespace), nn.NewPermissionRequestsValidator(mgr.GetClient(), allowClusterRole), l), l, namespace, networkPolicy)),
+	)
+
+	// the rollout progresses with the health of the network nodes, which is
+	// reported in their status, so all network node changes are watched; the
+	// device drivers of a device driver kind are validated against each other
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&ndddvrv1.DeviceDriver{}).
+		Watches(&source.Kind{Type: &ndddvrv1.NetworkNode{}}, &EnqueueRequestForBoundDeviceDriver{}).
+		Watches(&source.Kind{Type: &ndddvrv1.DeviceDriver{}}, &EnqueueRequestForDeviceDriversOfKind{
+			client: mgr.GetClient()}).
+		Complete(r)
+}
+
+// NewReconciler creates a new device driver reconciler.
+func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
+	r := &Reconciler{
+		client:      mgr.GetClient(),
+		ddFinalizer: resource.NewAPIFinalizer(mgr.GetClient(), finalizer),
+		log:         logging.NewNopLogger(),
+		record:      event.NewNopRecorder(),
+		shards:      nn.NewNopShardHooks(),
+	}
+
+	for _, f := range opts {
+		f(r)
+	}
+
+	return r
+}
+
+// isBound returns true if the network node is bound to the device driver.
+func isBound(n *ndddvrv1.NetworkNode, dd *ndddvrv1.DeviceDriver) bool {
+	ref := n.GetBoundDeviceDriver()
+	return ref != nil && ref.Name == dd.GetName() && ref.Namespace == dd.GetNamespace()
+}
+
+// references returns true if the network node references the device driver.
+func references(n *ndddvrv1.NetworkNode, dd *ndddvrv1.DeviceDriver) bool {
+	ref := n.GetDeviceDriverReference()
+	if ref == nil {
+		return false
+	}
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = ndddvrv1.DefaultNamespace
+	}
+	return ref.Name == dd.GetName() && namespace == dd.GetNamespace()
+}
+
+// isForceDeleted returns true if the device driver may be deleted while it is
+// still in use.
+func isForceDeleted(dd *ndddvrv1.DeviceDriver) bool {
+	force, err := strconv.ParseBool(dd.GetAnnotations()[ndddvrv1.AnnotationForceDelete])
+	return err == nil && force
+}
+
+// image returns the image of the device driver container.
+func image(dd *ndddvrv1.DeviceDriver) string {
+	switch {
+	case dd.Spec.Container != nil:
+		return dd.Spec.Container.Image
+	case dd.Spec.PodTemplate != nil && len(dd.Spec.PodTemplate.Spec.Containers) > 0:
+		return dd.Spec.PodTemplate.Spec.Containers[0].Image
+	}
+	return ""
+}
+
+// isHealthy returns true if the device driver of the network node is healthy.
+func isHealthy(n *ndddvrv1.NetworkNode) bool {
+	return n.GetCondition(ndddvrv1.ConditionKindDeviceDriverHealthy).Status == corev1.ConditionTrue
+}
+
+// isFailed returns true if the device driver of the network node failed to
+// become healthy, a device driver that is still deploying has not failed.
+func isFailed(n *ndddvrv1.NetworkNode) bool {
+	c := n.GetCondition(ndddvrv1.ConditionKindDeviceDriverHealthy)
+	return c.Status == corev1.ConditionFalse && c.Reason != ndddvrv1.ConditionReasonDeploying
+}
+
+// maxUnavailable returns the maximum number of unavailable network nodes of
+// the rollout, which is at least one.
+func maxUnavailable(s *ndddvrv1.RolloutStrategy, total int) (int, error) {
+	mu := intstr.FromInt(1)
+	if s.MaxUnavailable != nil {
+		mu = *s.MaxUnavailable
+	}
+	n, err := intstr.GetScaledValueFromIntOrPercent(&mu, total, false)
+	if err != nil {
+		return 0, errors.Wrap(err, errMaxUnavailable)
+	}
+	if n < 1 {
+		return 1, nil
+	}
+	return n, nil
+}
+
+// updateStatus updates the status of the device driver when it changed, the
+// device driver is reconciled on every status change of its network nodes.
+func (r *Reconciler) updateStatus(ctx context.Context, dd *ndddvrv1.DeviceDriver, status *ndddvrv1.DeviceDriverStatus) error {
+	if cmp.Equal(*status, dd.Status) {
+		return nil
+	}
+	return errors.Wrap(r.client.Status().Update(ctx, dd), errUpdateStatus)
+}
+
+// validate validates the device driver against the other device drivers of its
+// device driver kind, which are selected by the network nodes of the device
+// driver kind that do not reference a device driver.
+func (r *Reconciler) validate(ctx context.Context, dd *ndddvrv1.DeviceDriver) (nddv1.Condition, error) {
+	kind, ok := dd.GetLabels()[ndddvrv1.LabelDeviceDriverKind]
+	if !ok {
+		return ndddvrv1.DeviceDriverInvalid().WithMessage(errMissingKind), nil
+	}
+	l := &ndddvrv1.DeviceDriverList{}
+	if err := r.client.List(ctx, l, client.MatchingLabels{ndddvrv1.LabelDeviceDriverKind: kind}); err != nil {
+		return ndddvrv1.DeviceDriverInvalid(), errors.Wrap(err, errListDeviceDrivers)
+	}
+	var defaults []string
+	for i := range l.Items {
+		o := &l.Items[i]
+		if meta.WasDeleted(o) {
+			continue
+		}
+		if o.GetAnnotations()[ndddvrv1.AnnotationDefaultDriver] == "true" {
+			defaults = append(defaults, o.GetNamespace()+"/"+o.GetName())
+		}
+	}
+	sort.Strings(defaults)
+	if len(defaults) > 1 && dd.GetAnnotations()[ndddvrv1.AnnotationDefaultDriver] == "true" {
+		return ndddvrv1.DeviceDriverConflicting().WithMessage(errConflictDefault + ": " + strings.Join(defaults, ", ")), nil
+	}
+	return ndddvrv1.DeviceDriverValid(), nil
+}
+
+// hasShards returns true if the device driver is sharded or had shards, i.e.
+// the shards of the device driver need to be deployed or pruned.
+func hasShards(dd *ndddvrv1.DeviceDriver) bool {
+	return dd.Spec.Sharding != nil || dd.Status.Shards > 0
+}
+
+// shard assigns the network nodes bound to a sharded device driver to its
+// shards, deploys the shards and prunes the shards that are no longer needed.
+// The network nodes are assigned through the shard annotation, which is
+// removed from the network nodes that are no longer served by a shard of the
+// device driver. It returns the number of shards.
+func (r *Reconciler) shard(ctx context.Context, dd *ndddvrv1.DeviceDriver, l *ndddvrv1.NetworkNodeList) (int, error) {
+	var members []ndddvrv1.Nn
+	if dd.Spec.Sharding != nil && !meta.WasDeleted(dd) {
+		for i := range l.Items {
+			n := &l.Items[i]
+			if isBound(n, dd) && !n.GetMaintenance() {
+				members = append(members, n)
+			}
+		}
+	}
+	assigned := nn.AssignShards(dd, members)
+
+	for i := range l.Items {
+		n := &l.Items[i]
+		current := n.GetAnnotations()[ndddvrv1.AnnotationShard]
+		shard := assigned[n.GetName()]
+		if current == shard || (shard == "" && !nn.IsShardOf(dd, current)) {
+			continue
+		}
+		p := client.MergeFrom(n.DeepCopy())
+		if shard == "" {
+			meta.RemoveAnnotations(n, ndddvrv1.AnnotationShard)
+		} else {
+			meta.AddAnnotations(n, map[string]string{ndddvrv1.AnnotationShard: shard})
+		}
+		if err := r.client.Patch(ctx, n, p); err != nil {
+			return 0, errors.Wrap(err, errAssignShard)
+		}
+	}
+
+	shards := make(map[string][]ndddvrv1.Nn)
+	for _, n := range members {
+		shard := assigned[n.GetName()]
+		shards[shard] = append(shards[shard], n)
+	}
+	names := make([]string, 0, len(shards))
+	for shard := range shards {
+		names = append(names, shard)
+	}
+	sort.Strings(names)
+
+	keep := make(map[string]bool, len(names))
+	for _, shard := range names {
+		if err := r.shards.Deploy(ctx, dd, shard, shards[shard]); err != nil {
+			return 0, errors.Wrap(err, errDeployShard)
+		}
+		keep[shard] = true
+	}
+	if err := r.shards.Prune(ctx, dd, keep); err != nil {
+		return 0, errors.Wrap(err, errPruneShards)
+	}
+	return len(names), nil
+}
+
+// +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=devicedrivers,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=devicedrivers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=devicedrivers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=networknodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=list;watch;get;patch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch;create;update;patch;delete;escalate;bind
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;create;update;patch;delete;escalate;bind
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch
+
+// Reconcile the status and the rollout of a device driver. The deletion of a
+// device driver that is used by network nodes has to be confirmed with the
+// force delete annotation. The network nodes running a
+// previous revision of the device driver are admitted to the revision of the
+// device driver, the canary network nodes first and one at a time, then the
+// other network nodes within the max unavailable network nodes. The rollout
+// halts when network nodes running the revision fail to become healthy. The
+// network nodes of a sharded device driver are assigned to its shards, which
+// are deployed by the device driver controller and rolled out at once.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) { // nolint:gocyclo
+	log := r.log.WithValues("request", req)
+	log.Debug("Device Driver", "NameSpace", req.NamespacedName)
+
+	ctx, cancel := context.WithTimeout(ctx, reconcileTimeout)
+	defer cancel()
+
+	dd := &ndddvrv1.DeviceDriver{}
+	if err := r.client.Get(ctx, req.NamespacedName, dd); err != nil {
+		log.Debug(errGetDeviceDriver, "error", err)
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetDeviceDriver)
+	}
+
+	status := dd.Status.DeepCopy()
+
+	l := &ndddvrv1.NetworkNodeList{}
+	if err := r.client.List(ctx, l); err != nil {
+		log.Debug(errListNetworkNodes, "error", err)
+		return reconcile.Result{}, errors.Wrap(err, errListNetworkNodes)
+	}
+
+	// the network nodes bound to or referencing the device driver use it
+	var bound, users []string
+	for i := range l.Items {
+		n := &l.Items[i]
+		if isBound(n, dd) {
+			bound = append(bound, n.GetName())
+		}
+		if isBound(n, dd) || references(n, dd) {
+			users = append(users, n.GetName())
+		}
+	}
+	sort.Strings(bound)
+	sort.Strings(users)
+	dd.Status.BoundNetworkNodes = bound
+
+	if meta.WasDeleted(dd) {
+		if len(users) > 0 {
+			if !isForceDeleted(dd) {
+				msg := "blocking deletion while network nodes use the device driver, confirm with the " + ndddvrv1.AnnotationForceDelete + " annotation: " + strings.Join(users, ", ")
+				log.Debug("blocking deletion", "users", users)
+				r.record.Event(dd, event.Warning(reasonSync, errors.New(msg)))
+				dd.SetConditions(ndddvrv1.Terminating().WithMessage(msg))
+				// the network nodes are watched, so we'll be requeued when they go
+				return reconcile.Result{}, r.updateStatus(ctx, dd, status)
+			}
+			r.record.Event(dd, event.Warning(reasonSync, errors.Errorf("force deleted while in use by %d network nodes", len(users))))
+		}
+		if hasShards(dd) {
+			if _, err := r.shard(ctx, dd, l); err != nil {
+				log.Debug(errPruneShards, "error", err)
+				r.record.Event(dd, event.Warning(reasonShard, err))
+				return reconcile.Result{RequeueAfter: longWait}, nil
+			}
+		}
+		if err := r.ddFinalizer.RemoveFinalizer(ctx, dd); err != nil {
+			log.Debug(errRemoveFinalizer, "error", err)
+			r.record.Event(dd, event.Warning(reasonSync, errors.Wrap(err, errRemoveFinalizer)))
+			return reconcile.Result{RequeueAfter: longWait}, nil
+		}
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.ddFinalizer.AddFinalizer(ctx, dd); err != nil {
+		log.Debug(errAddFinalizer, "error", err)
+		r.record.Event(dd, event.Warning(reasonSync, errors.Wrap(err, errAddFinalizer)))
+		return reconcile.Result{RequeueAfter: longWait}, nil
+	}
+
+	valid, err := r.validate(ctx, dd)
+	if err != nil {
+		log.Debug(errListDeviceDrivers, "error", err)
+		return reconcile.Result{}, errors.Wrap(err, errListDeviceDrivers)
+	}
+	if valid.Status != corev1.ConditionTrue && !dd.GetCondition(ndddvrv1.ConditionKindDeviceDriverValid).Equal(valid) {
+		r.record.Event(dd, event.Warning(reasonSync, errors.New(valid.Message)))
+	}
+	dd.SetConditions(valid)
+
+	// only the sharded device drivers and the device drivers that had shards
+	// have shards to deploy or prune
+	if hasShards(dd) {
+		shards, err := r.shard(ctx, dd, l)
+		if err != nil {
+			log.Debug(errDeployShard, "error", err)
+			r.record.Event(dd, event.Warning(reasonShard, err))
+			dd.SetConditions(ndddvrv1.RolloutHalted().WithMessage(err.Error()))
+			return reconcile.Result{RequeueAfter: longWait}, r.updateStatus(ctx, dd, status)
+		}
+		dd.Status.Shards = shards
+	}
+
+	canary := labels.Nothing()
+	if dd.Spec.Rollout != nil && dd.Spec.Rollout.CanarySelector != nil {
+		s, err := metav1.LabelSelectorAsSelector(dd.Spec.Rollout.CanarySelector)
+		if err != nil {
+			log.Debug(errCanarySelector, "error", err)
+			r.record.Event(dd, event.Warning(reasonRollout, errors.Wrap(err, errCanarySelector)))
+			dd.SetConditions(ndddvrv1.RolloutHalted().WithMessage(errors.Wrap(err, errCanarySelector).Error()))
+			return reconcile.Result{}, r.updateStatus(ctx, dd, status)
+		}
+		canary = s
+	}
+
+	// classify the network nodes using the device driver, the network nodes in
+	// maintenance are not rolled out
+	revision := nn.DeviceDriverRevision(&dd.Spec)
+	var total, updated, unavailable, canariesPending, canariesInFlight int
+	var failed []string
+	var canaries, others, unhealthy []*ndddvrv1.NetworkNode
+	for i := range l.Items {
+		n := &l.Items[i]
+		if !isBound(n, dd) || n.GetMaintenance() {
+			continue
+		}
+		total++
+		running := n.GetUsedDeviceDriverSpec() != nil && nn.DeviceDriverRevision(n.GetUsedDeviceDriverSpec()) == revision
+		admitted := n.GetAnnotations()[ndddvrv1.AnnotationDriverRevision] == revision
+		isCanary := canary.Matches(labels.Set(n.GetLabels()))
+		if running {
+			updated++
+			if isFailed(n) {
+				failed = append(failed, n.GetName())
+			}
+		}
+		if !isHealthy(n) || (admitted && !running) {
+			unavailable++
+		}
+		if isCanary && !(running && isHealthy(n)) {
+			canariesPending++
+			if admitted || running {
+				canariesInFlight++
+			}
+		}
+		if running || admitted {
+			continue
+		}
+		switch {
+		case isCanary:
+			canaries = append(canaries, n)
+		case !isHealthy(n):
+			unhealthy = append(unhealthy, n)
+		default:
+			others = append(others, n)
+		}
+	}
+	sort.Strings(failed)
+	sort.Slice(canaries, func(i, j int) bool { return canaries[i].GetName() < canaries[j].GetName() })
+	sort.Slice(others, func(i, j int) bool { return others[i].GetName() < others[j].GetName() })
+	sort.Slice(unhealthy, func(i, j int) bool { return unhealthy[i].GetName() < unhealthy[j].GetName() })
+
+	// the image and health of all bound network nodes are reported, including
+	// the network nodes in maintenance
+	var healthy, outdated int
+	for i := range l.Items {
+		n := &l.Items[i]
+		if !isBound(n, dd) {
+			continue
+		}
+		if isHealthy(n) {
+			healthy++
+		}
+		if n.GetUsedDeviceDriverSpec() != nil && nn.DeviceDriverRevision(n.GetUsedDeviceDriverSpec()) != revision {
+			outdated++
+		}
+	}
+
+	dd.Status.ObservedGeneration = dd.GetGeneration()
+	dd.Status.Revision = revision
+	dd.Status.Image = image(dd)
+	dd.Status.NetworkNodes = len(bound)
+	dd.Status.UpdatedNetworkNodes = updated
+	dd.Status.OutdatedNetworkNodes = outdated
+	dd.Status.HealthyNetworkNodes = healthy
+	dd.Status.UnavailableNetworkNodes = unavailable
+	dd.Status.FailedNetworkNodes = failed
+	log = log.WithValues("revision", revision, "nodes", total, "updated", updated, "unavailable", unavailable)
+
+	// without a rollout strategy all network nodes are rolled out at once by
+	// the network node controller, the shards are rolled out at once as well
+	if dd.Spec.Rollout == nil || dd.Spec.Sharding != nil {
+		if updated == total {
+			dd.SetConditions(ndddvrv1.RolloutComplete())
+			return reconcile.Result{}, r.updateStatus(ctx, dd, status)
+		}
+		dd.SetConditions(ndddvrv1.RolloutProgressing())
+		return reconcile.Result{RequeueAfter: longWait}, r.updateStatus(ctx, dd, status)
+	}
+
+	var admit []*ndddvrv1.NetworkNode
+	switch {
+	case len(failed) > 0:
+		msg := errRolloutHalted + ": " + strings.Join(failed, ", ")
+		log.Debug(errRolloutHalted, "failed", failed)
+		if dd.GetCondition(ndddvrv1.ConditionKindRollout).Reason != ndddvrv1.ConditionReasonRolloutHalted {
+			r.record.Event(dd, event.Warning(reasonRollout, errors.New(msg)))
+		}
+		dd.SetConditions(ndddvrv1.RolloutHalted().WithMessage(msg))
+	case dd.Spec.Rollout.Paused != nil && *dd.Spec.Rollout.Paused:
+		dd.SetConditions(ndddvrv1.RolloutPaused())
+	case updated == total:
+		dd.SetConditions(ndddvrv1.RolloutComplete())
+	case canariesPending > 0:
+		// the canaries are rolled out one at a time
+		if canariesInFlight == 0 && len(canaries) > 0 {
+			admit = canaries[:1]
+		}
+		dd.SetConditions(ndddvrv1.RolloutCanary())
+	default:
+		mu, err := maxUnavailable(dd.Spec.Rollout, total)
+		if err != nil {
+			log.Debug(errMaxUnavailable, "error", err)
+			r.record.Event(dd, event.Warning(reasonRollout, err))
+			dd.SetConditions(ndddvrv1.RolloutHalted().WithMessage(err.Error()))
+			return reconcile.Result{}, r.updateStatus(ctx, dd, status)
+		}
+		// the outdated network nodes that are unhealthy are admitted first and
+		// outside of the budget, as they cannot become less available, the
+		// budget only applies to the healthy network nodes
+		admit = unhealthy
+		if budget := mu - (unavailable - len(unhealthy)); budget > 0 {
+			if budget > len(others) {
+				budget = len(others)
+			}
+			admit = append(admit, others[:budget]...)
+		}
+		dd.SetConditions(ndddvrv1.RolloutProgressing())
+	}
+
+	for _, n := range admit {
+		log.Debug("Admit network node", "networkNode", n.GetName())
+		p := client.MergeFrom(n.DeepCopy())
+		meta.AddAnnotations(n, map[string]string{ndddvrv1.AnnotationDriverRevision: revision})
+		if err := r.client.Patch(ctx, n, p); err != nil {
+			log.Debug(errAdmitNetworkNode, "error", err)
+			r.record.Event(dd, event.Warning(reasonRollout, errors.Wrap(err, errAdmitNetworkNode)))
+			return reconcile.Result{RequeueAfter: longWait}, r.updateStatus(ctx, dd, status)
+		}
+		r.record.Event(dd, event.Normal(reasonRollout, "Rolling out revision "+revision+" to network node "+n.GetName()))
+	}
+
+	// the network nodes are watched, the requeue only guards the progress of
+	// a rollout that is not complete
+	if updated == total {
+		return reconcile.Result{}, r.updateStatus(ctx, dd, status)
+	}
+	return reconcile.Result{RequeueAfter: longWait}, r.updateStatus(ctx, dd, status)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dd
+
+import (
+	"context"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type adder interface {
+	Add(item interface{})
+}
+
+// EnqueueRequestForBoundDeviceDriver enqueues a request for the device driver
+// a network node is bound to and the device driver it references.
+type EnqueueRequestForBoundDeviceDriver struct{}
+
+// Create enqueues a request for the device driver of the NetworkNode.
+func (e *EnqueueRequestForBoundDeviceDriver) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Update enqueues a request for the device drivers of the NetworkNode, the
+// network node can be bound to another device driver.
+func (e *EnqueueRequestForBoundDeviceDriver) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.ObjectOld, q)
+	e.add(evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for the device driver of the NetworkNode.
+func (e *EnqueueRequestForBoundDeviceDriver) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic enqueues a request for the device driver of the NetworkNode.
+func (e *EnqueueRequestForBoundDeviceDriver) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+func (e *EnqueueRequestForBoundDeviceDriver) add(obj runtime.Object, queue adder) {
+	nn, ok := obj.(*ndddvrv1.NetworkNode)
+	if !ok {
+		return
+	}
+	if ref := nn.GetBoundDeviceDriver(); ref != nil {
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}})
+	}
+	if ref := nn.GetDeviceDriverReference(); ref != nil {
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = ndddvrv1.DefaultNamespace
+		}
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: ref.Name}})
+	}
+}
+
+// EnqueueRequestForDeviceDriversOfKind enqueues a request for all device
+// drivers of the device driver kind of a device driver, such that conflicts
+// between the device drivers of a device driver kind are reported.
+type EnqueueRequestForDeviceDriversOfKind struct {
+	client client.Client
+}
+
+// Create enqueues a request for all device drivers of the kind of the DeviceDriver.
+func (e *EnqueueRequestForDeviceDriversOfKind) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Update enqueues a request for all device drivers of the kinds of the DeviceDriver.
+func (e *EnqueueRequestForDeviceDriversOfKind) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.ObjectOld, q)
+	e.add(evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for all device drivers of the kind of the DeviceDriver.
+func (e *EnqueueRequestForDeviceDriversOfKind) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic enqueues a request for all device drivers of the kind of the DeviceDriver.
+func (e *EnqueueRequestForDeviceDriversOfKind) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+func (e *EnqueueRequestForDeviceDriversOfKind) add(obj runtime.Object, queue adder) {
+	dd, ok := obj.(*ndddvrv1.DeviceDriver)
+	if !ok {
+		return
+	}
+	kind, ok := dd.GetLabels()[ndddvrv1.LabelDeviceDriverKind]
+	if !ok {
+		return
+	}
+
+	dds := &ndddvrv1.DeviceDriverList{}
+	if err := e.client.List(context.TODO(), dds, client.MatchingLabels{ndddvrv1.LabelDeviceDriverKind: kind}); err != nil {
+		return
+	}
+	for _, o := range dds.Items {
+		if o.GetName() == dd.GetName() && o.GetNamespace() == dd.GetNamespace() {
+			continue
+		}
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}})
+	}
+}
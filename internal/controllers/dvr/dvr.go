@@ -19,18 +19,23 @@ package dvr
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/netw-device-driver/ndd-core/internal/controllers/dvr/dd"
 	"github.com/netw-device-driver/ndd-core/internal/controllers/dvr/nn"
+	"github.com/netw-device-driver/ndd-core/internal/controllers/dvr/nnu"
 	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
 )
 
-// Setup device driver controllers.
-func Setup(mgr ctrl.Manager, l logging.Logger, namespace string) error {
-	for _, setup := range []func(ctrl.Manager, logging.Logger, string) error{
+// Setup device driver controllers. The permissions the device drivers request
+// are validated against the allowClusterRole, the device driver pods are
+// isolated with network policies when networkPolicy is set.
+func Setup(mgr ctrl.Manager, l logging.Logger, namespace, allowClusterRole string, networkPolicy bool) error {
+	for _, setup := range []func(ctrl.Manager, logging.Logger, string, string, bool) error{
 		nn.Setup,
+		dd.Setup,
 	} {
-		if err := setup(mgr, l, namespace); err != nil {
+		if err := setup(mgr, l, namespace, allowClusterRole, networkPolicy); err != nil {
 			return err
 		}
 	}
-	return nil
+	return nnu.Setup(mgr, l, namespace)
 }
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/pki"
+	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	"github.com/netw-device-driver/ndd-runtime/pkg/utils"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Errors
+	errGetServerCert    = "cannot get device driver server certificate"
+	errIssueServerCert  = "cannot issue device driver server certificate"
+	errApplyServerCert  = "cannot apply device driver server certificate"
+	errDeleteServerCert = "cannot delete device driver server certificate"
+)
+
+// buildServerCertSecret builds the secret holding the serving certificate for
+// the services of the device driver. The certificate in the current secret is
+// kept as long as it is valid, otherwise a new certificate is issued by the
+// certificate authority.
+func buildServerCertSecret(ctx context.Context, c client.Reader, ca *pki.CA, om metav1.ObjectMeta, commonName string, services []string) (*corev1.Secret, error) {
+	current := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: om.Namespace, Name: om.Name}, current)
+	if resource.IgnoreNotFound(err) != nil {
+		return nil, errors.Wrap(err, errGetServerCert)
+	}
+	data, err := ca.ServingCertificate(current.Data, commonName, pki.ServiceDNSNames(services, om.Namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, errIssueServerCert)
+	}
+	return &corev1.Secret{
+		ObjectMeta: om,
+		Type:       corev1.SecretTypeTLS,
+		Data:       data,
+	}, nil
+}
+
+// serverCertObjectMeta returns the object meta of the secret holding the
+// serving certificate of the device driver for the network node.
+func serverCertObjectMeta(nn ndddvrv1.Nn, namespace string) metav1.ObjectMeta {
+	om := nnObjectMeta(nn, namespace)
+	om.Name = strings.Join([]string{ndddvrv1.PrefixServerCertSecret, nn.GetName()}, "-")
+	return om
+}
+
+func emptyServerCertSecret(nn ndddvrv1.Nn, namespace string) *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: serverCertObjectMeta(nn, namespace)}
+}
+
+// serverTLSConfig returns the server tls config of the device driver config
+// document.
+func serverTLSConfig() *ndddvrv1.DeviceDriverServerTLSConfig {
+	return &ndddvrv1.DeviceDriverServerTLSConfig{
+		CA:   path.Join(ndddvrv1.ServerCertMountPath, pki.KeyCACert),
+		Cert: path.Join(ndddvrv1.ServerCertMountPath, corev1.TLSCertKey),
+		Key:  path.Join(ndddvrv1.ServerCertMountPath, corev1.TLSPrivateKeyKey),
+	}
+}
+
+// serverCertVolume returns the volume and the volume mount of the secret
+// holding the serving certificate of the device driver.
+func serverCertVolume(secret string) (corev1.Volume, corev1.VolumeMount) {
+	v := corev1.Volume{
+		Name: ndddvrv1.ServerCertVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secret,
+				Optional:   utils.BoolPtr(true),
+			},
+		},
+	}
+	vm := corev1.VolumeMount{
+		Name:      ndddvrv1.ServerCertVolume,
+		MountPath: ndddvrv1.ServerCertMountPath,
+		ReadOnly:  true,
+	}
+	return v, vm
+}
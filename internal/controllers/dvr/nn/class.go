@@ -0,0 +1,39 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// networkNodeClass returns the spec of the network node class of the network
+// node, it is nil when the network node does not reference a class.
+func networkNodeClass(ctx context.Context, c client.Reader, nn ndddvrv1.Nn) (*ndddvrv1.NetworkNodeClassSpec, error) {
+	name := nn.GetNetworkNodeClassName()
+	if name == "" {
+		return nil, nil
+	}
+	class := &ndddvrv1.NetworkNodeClass{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, class); err != nil {
+		return nil, err
+	}
+	return &class.Spec, nil
+}
@@ -17,6 +17,10 @@ limitations under the License.
 package nn
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"path"
 	"strings"
 
 	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
@@ -25,7 +29,43 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func buildConfigMap(nn ndddvrv1.Nn, namespace string) *corev1.ConfigMap {
+func buildDeviceDriverConfig(nn ndddvrv1.Nn) *ndddvrv1.DeviceDriverConfig {
+	cfg := &ndddvrv1.DeviceDriverConfig{
+		Version:          ndddvrv1.DeviceDriverConfigVersion,
+		NetworkNodeName:  nn.GetName(),
+		DeviceDriverKind: nn.GetDeviceDriverKind(),
+		GrpcServerPort:   nn.GetGrpcServerPort(),
+		Target: ndddvrv1.DeviceDriverTargetConfig{
+			Address:     nn.GetTargetAddress(),
+			Proxy:       nn.GetTargetProxy(),
+			Encoding:    nn.GetTargetEncoding(),
+			SkipVerify:  nn.GetTargetSkipVerify(),
+			Insecure:    nn.GetTargetInsecure(),
+			Credentials: ndddvrv1.CredentialsMountPath,
+		},
+	}
+	if nn.GetTargetTLSCredentialsName() != "" {
+		cfg.Target.TLSCA = path.Join(ndddvrv1.TLSMountPath, ndddvrv1.TLSCAKey)
+		cfg.Target.TLSCert = path.Join(ndddvrv1.TLSMountPath, ndddvrv1.TLSCertKey)
+		cfg.Target.TLSKey = path.Join(ndddvrv1.TLSMountPath, ndddvrv1.TLSKeyKey)
+	}
+	if nn.GetTargetProxyCredentialsName() != "" {
+		cfg.Target.ProxyCredentials = ndddvrv1.ProxyCredentialsMountPath
+	}
+	return cfg
+}
+
+// buildConfigMap builds the configmap of the device driver for the network
+// node, the grpc server of the device driver serves tls when serverTLS is set.
+func buildConfigMap(nn ndddvrv1.Nn, namespace string, serverTLS bool) (*corev1.ConfigMap, error) {
+	c := buildDeviceDriverConfig(nn)
+	if serverTLS {
+		c.ServerTLS = serverTLSConfig()
+	}
+	cfg, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      strings.Join([]string{ndddvrv1.PrefixConfigmap, nn.GetName()}, "-"),
@@ -35,5 +75,14 @@ func buildConfigMap(nn ndddvrv1.Nn, namespace string) *corev1.ConfigMap {
 			},
 			OwnerReferences: []metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(nn, ndddvrv1.NetworkNodeGroupVersionKind))},
 		},
-	}
+		Data: map[string]string{
+			ndddvrv1.ConfigmapJsonConfig: string(cfg),
+		},
+	}, nil
+}
+
+// configHash returns the hash of the device driver config document, which is
+// used to roll the device driver pod when the config changes.
+func configHash(cm *corev1.ConfigMap) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(cm.Data[ndddvrv1.ConfigmapJsonConfig])))
 }
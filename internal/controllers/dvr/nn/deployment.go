@@ -17,6 +17,9 @@ limitations under the License.
 package nn
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
 	"strings"
 
 	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
@@ -27,7 +30,131 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func buildDeployment(nn ndddvrv1.Nn, c *corev1.Container, namespace string) *appsv1.Deployment {
+const (
+	// environment parameters of the proxy used by the device driver
+	proxyEnv         = "TARGET_PROXY"
+	proxyUsernameEnv = "TARGET_PROXY_USERNAME"
+	proxyPasswordEnv = "TARGET_PROXY_PASSWORD"
+)
+
+// buildDeployment builds the deployment of the device driver from the pod
+// template. The first container of the pod template is the device driver
+// container, the other containers are sidecars. The ndd owned fields of the pod
+// template are overwritten, the user owned fields are kept as is. The serving
+// certificate of the device driver is mounted when serverTLS is set.
+func buildDeployment(nn ndddvrv1.Nn, pt *corev1.PodTemplateSpec, namespace string, serverTLS bool, podAnnotations map[string]string) *appsv1.Deployment {
+	t := pt.DeepCopy()
+	if len(t.Spec.Containers) == 0 {
+		t.Spec.Containers = []corev1.Container{{}}
+	}
+
+	// ndd owned labels and annotations
+	if t.Labels == nil {
+		t.Labels = map[string]string{}
+	}
+	t.Labels[ndddvrv1.LabelApplication] = strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-")
+	// the shard labels identify the pods of the shards
+	delete(t.Labels, ndddvrv1.LabelDeviceDriverName)
+	delete(t.Labels, ndddvrv1.LabelDeviceDriverNs)
+	for k, v := range podAnnotations {
+		if t.Annotations == nil {
+			t.Annotations = map[string]string{}
+		}
+		t.Annotations[k] = v
+	}
+
+	// ndd owned service account
+	t.Spec.ServiceAccountName = strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-")
+
+	// ndd owned volumes and their mounts in the device driver container
+	volumes := []corev1.Volume{
+		{
+			Name: ndddvrv1.ConfigmapVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: strings.Join([]string{ndddvrv1.PrefixConfigmap, nn.GetName()}, "-"),
+					},
+				},
+			},
+		},
+		{
+			Name: ndddvrv1.CredentialsVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: strings.Join([]string{ndddvrv1.PrefixCredentialsSecret, nn.GetName()}, "-"),
+				},
+			},
+		},
+	}
+	mounts := []corev1.VolumeMount{
+		{
+			Name:      ndddvrv1.ConfigmapVolume,
+			MountPath: ndddvrv1.ConfigmapMountPath,
+			ReadOnly:  true,
+		},
+		{
+			Name:      ndddvrv1.CredentialsVolume,
+			MountPath: ndddvrv1.CredentialsMountPath,
+			ReadOnly:  true,
+		},
+	}
+	if nn.GetTargetTLSCredentialsName() != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: ndddvrv1.TLSVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: strings.Join([]string{ndddvrv1.PrefixTLSSecret, nn.GetName()}, "-"),
+				},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      ndddvrv1.TLSVolume,
+			MountPath: ndddvrv1.TLSMountPath,
+			ReadOnly:  true,
+		})
+	}
+	if serverTLS {
+		v, vm := serverCertVolume(strings.Join([]string{ndddvrv1.PrefixServerCertSecret, nn.GetName()}, "-"))
+		volumes = append(volumes, v)
+		mounts = append(mounts, vm)
+	}
+	// the proxy credentials are projected from the credentials secret in the
+	// same layout as the credentials
+	var env []corev1.EnvVar
+	if nn.GetTargetProxy() != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  proxyEnv,
+			Value: nn.GetTargetProxy(),
+		})
+	}
+	if nn.GetTargetProxyCredentialsName() != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: ndddvrv1.ProxyCredentialsVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: strings.Join([]string{ndddvrv1.PrefixCredentialsSecret, nn.GetName()}, "-"),
+					Items: []corev1.KeyToPath{
+						{Key: ndddvrv1.ProxyUsernameKey, Path: ndddvrv1.CredentialsUsernameKey},
+						{Key: ndddvrv1.ProxyPasswordKey, Path: ndddvrv1.CredentialsPasswordKey},
+					},
+				},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      ndddvrv1.ProxyCredentialsVolume,
+			MountPath: ndddvrv1.ProxyCredentialsMountPath,
+			ReadOnly:  true,
+		})
+		env = append(env,
+			secretEnv(proxyUsernameEnv, strings.Join([]string{ndddvrv1.PrefixCredentialsSecret, nn.GetName()}, "-"), ndddvrv1.ProxyUsernameKey),
+			secretEnv(proxyPasswordEnv, strings.Join([]string{ndddvrv1.PrefixCredentialsSecret, nn.GetName()}, "-"), ndddvrv1.ProxyPasswordKey),
+		)
+	}
+	t.Spec.Volumes = append(userVolumes(t.Spec.Volumes), volumes...)
+	t.Spec.Containers[0].Env = append(userEnv(t.Spec.Containers[0].Env), env...)
+	t.Spec.Containers[0].VolumeMounts = append(userVolumeMounts(t.Spec.Containers[0].VolumeMounts), mounts...)
+
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      strings.Join([]string{ndddvrv1.PrefixDeployment, nn.GetName()}, "-"),
@@ -44,19 +171,74 @@ func buildDeployment(nn ndddvrv1.Nn, c *corev1.Container, namespace string) *app
 					ndddvrv1.LabelApplication: strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-"),
 				},
 			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						ndddvrv1.LabelApplication: strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-"),
-					},
-				},
-				Spec: corev1.PodSpec{
-					ServiceAccountName: strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-"),
-					Containers: []corev1.Container{
-						*c,
-					},
-				},
+			Template: *t,
+		},
+	}
+}
+
+// secretEnv returns an environment variable holding the key of the secret.
+func secretEnv(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
 			},
 		},
 	}
 }
+
+// userEnv returns the environment variables of the device driver container
+// that are not owned by ndd.
+func userEnv(env []corev1.EnvVar) []corev1.EnvVar {
+	r := make([]corev1.EnvVar, 0, len(env))
+	for _, e := range env {
+		if e.Name != proxyEnv && e.Name != proxyUsernameEnv && e.Name != proxyPasswordEnv {
+			r = append(r, e)
+		}
+	}
+	return r
+}
+
+// isNddVolume returns true if the volume name is reserved for the volumes ndd
+// mounts in the device driver container.
+func isNddVolume(name string) bool {
+	return name == ndddvrv1.ConfigmapVolume || name == ndddvrv1.CredentialsVolume || name == ndddvrv1.TLSVolume ||
+		name == ndddvrv1.ProxyCredentialsVolume || name == ndddvrv1.ServerCertVolume
+}
+
+// userVolumes returns the volumes of the pod template that are not owned by ndd.
+func userVolumes(vs []corev1.Volume) []corev1.Volume {
+	r := make([]corev1.Volume, 0, len(vs))
+	for _, v := range vs {
+		if !isNddVolume(v.Name) {
+			r = append(r, v)
+		}
+	}
+	return r
+}
+
+// userVolumeMounts returns the volume mounts of the device driver container
+// that are not owned by ndd.
+func userVolumeMounts(vms []corev1.VolumeMount) []corev1.VolumeMount {
+	r := make([]corev1.VolumeMount, 0, len(vms))
+	for _, vm := range vms {
+		if !isNddVolume(vm.Name) {
+			r = append(r, vm)
+		}
+	}
+	return r
+}
+
+// specHash returns the hash of the effective device driver configuration, i.e.
+// the specs of the device driver deployment and service. The deployment holds
+// the hashes of the configmap and the secrets in its pod annotations.
+func specHash(d *appsv1.Deployment, s *corev1.Service) string {
+	h := sha256.New()
+	for _, spec := range []interface{}{d.Spec, s.Spec} {
+		b, _ := json.Marshal(spec) // nolint:errcheck
+		h.Write(b)                 // nolint:errcheck
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
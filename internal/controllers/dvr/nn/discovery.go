@@ -0,0 +1,216 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/pki"
+	"github.com/netw-device-driver/ndd-core/pkg/discovery/discoverypb"
+	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/utils"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	discoveryTimeout = 10 * time.Second
+
+	// the common name of the client certificate of the core
+	coreCommonName = "ndd-core"
+
+	// Errors
+	errDialDeviceDriver  = "cannot connect to device driver"
+	errDiscoverDevice    = "cannot discover device through device driver"
+	errNoCA              = "device driver serves tls without a certificate authority"
+	errClientCertificate = "cannot issue client certificate"
+)
+
+// A Discoverer discovers the details of the network device through the
+// device driver of the network node.
+type Discoverer interface {
+	// Discover returns the details of the network device
+	Discover(ctx context.Context, nn ndddvrv1.Nn) (*ndddvrv1.DeviceDetails, error)
+}
+
+// A GrpcDiscovererOption configures a GrpcDiscoverer.
+type GrpcDiscovererOption func(*GrpcDiscoverer)
+
+// WithDeviceDriverTargetFn specifies how the GrpcDiscoverer resolves the grpc
+// target of the device driver serving a network node.
+func WithDeviceDriverTargetFn(f func(nn ndddvrv1.Nn) string) GrpcDiscovererOption {
+	return func(d *GrpcDiscoverer) {
+		d.target = f
+	}
+}
+
+// WithDialOptions specifies the grpc dial options the GrpcDiscoverer uses to
+// connect to the device driver.
+func WithDialOptions(opts ...grpc.DialOption) GrpcDiscovererOption {
+	return func(d *GrpcDiscoverer) {
+		d.dialOpts = opts
+	}
+}
+
+// WithCertificateAuthority specifies the reader of the certificate authority
+// of the core, with which the GrpcDiscoverer issues its client certificate to
+// connect to the device drivers that serve tls.
+func WithCertificateAuthority(c client.Reader) GrpcDiscovererOption {
+	return func(d *GrpcDiscoverer) {
+		d.client = c
+	}
+}
+
+// GrpcDiscoverer discovers network devices using the discovery service of the
+// device driver.
+type GrpcDiscoverer struct {
+	log       logging.Logger
+	target    func(nn ndddvrv1.Nn) string
+	dialOpts  []grpc.DialOption
+	client    client.Reader
+	namespace string
+
+	// the client certificate of the core, which is renewed when needed
+	mu   sync.Mutex
+	cert map[string][]byte
+}
+
+// NewGrpcDiscoverer creates a new GrpcDiscoverer that connects to the device
+// driver through the endpoint published in the status of the network node.
+func NewGrpcDiscoverer(log logging.Logger, namespace string, opts ...GrpcDiscovererOption) *GrpcDiscoverer {
+	d := &GrpcDiscoverer{
+		log: log,
+		target: func(nn ndddvrv1.Nn) string {
+			return deviceDriverServiceTarget(nn, namespace)
+		},
+		dialOpts:  []grpc.DialOption{grpc.WithInsecure()},
+		namespace: namespace,
+	}
+	for _, f := range opts {
+		f(d)
+	}
+	return d
+}
+
+// Discover returns the details of the network device reported by the device
+// driver.
+func (d *GrpcDiscoverer) Discover(ctx context.Context, nn ndddvrv1.Nn) (*ndddvrv1.DeviceDetails, error) {
+	target := d.target(nn)
+	log := d.log.WithValues("name", nn.GetName(), "target", target)
+	log.Debug("Discover device")
+
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	opts := d.dialOpts
+	if e := nn.GetDeviceDriverEndpoint(); e != nil && e.ServerName != "" && d.client != nil {
+		creds, err := d.transportCredentials(ctx, e.ServerName)
+		if err != nil {
+			return nil, errors.Wrap(err, errDialDeviceDriver)
+		}
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	}
+
+	conn, err := grpc.DialContext(ctx, target, append(opts, grpc.WithBlock())...)
+	if err != nil {
+		return nil, errors.Wrap(err, errDialDeviceDriver)
+	}
+	defer conn.Close() // nolint:errcheck
+
+	rsp, err := discoverypb.NewDiscoveryClient(conn).Get(ctx, &discoverypb.Request{NetworkNodeName: nn.GetName()})
+	if err != nil {
+		return nil, errors.Wrap(err, errDiscoverDevice)
+	}
+	log.Debug("Discovered device", "details", rsp)
+
+	dt := nddv1.DeviceType(rsp.GetType())
+	return &ndddvrv1.DeviceDetails{
+		Type:         &dt,
+		HostName:     utils.StringPtr(rsp.GetHostname()),
+		Kind:         utils.StringPtr(rsp.GetKind()),
+		SwVersion:    utils.StringPtr(rsp.GetSwVersion()),
+		MacAddress:   utils.StringPtr(rsp.GetMacAddress()),
+		SerialNumber: utils.StringPtr(rsp.GetSerialNumber()),
+	}, nil
+}
+
+// transportCredentials returns the credentials for mutual tls with a device
+// driver serving a certificate for the server name, the client certificate of
+// the core is issued by the certificate authority.
+func (d *GrpcDiscoverer) transportCredentials(ctx context.Context, serverName string) (credentials.TransportCredentials, error) {
+	ca, err := pki.LoadCA(ctx, d.client, d.namespace)
+	if err != nil {
+		return nil, err
+	}
+	if ca == nil {
+		return nil, errors.New(errNoCA)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cert, err = ca.ClientCertificate(d.cert, coreCommonName); err != nil {
+		return nil, errors.Wrap(err, errClientCertificate)
+	}
+	cert, err := tls.X509KeyPair(d.cert[corev1.TLSCertKey], d.cert[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, errors.Wrap(err, errClientCertificate)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca.CertPEM())
+	return credentials.NewTLS(&tls.Config{
+		ServerName:   serverName,
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+// deviceDriverServiceTarget returns the grpc target of the device driver of the
+// network node, which is the endpoint published in the status of the network
+// node or the service of the network node when no endpoint is published.
+func deviceDriverServiceTarget(nn ndddvrv1.Nn, namespace string) string {
+	if e := nn.GetDeviceDriverEndpoint(); e != nil {
+		return net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	}
+	service := strings.Join([]string{ndddvrv1.PrefixService, nn.GetName()}, "-")
+	return net.JoinHostPort(ndddvrv1.ServiceDNSName(service, namespace), strconv.Itoa(nn.GetGrpcServerPort()))
+}
+
+// NopDiscoverer does not discover anything.
+type NopDiscoverer struct{}
+
+// NewNopDiscoverer creates a discoverer that does nothing.
+func NewNopDiscoverer() *NopDiscoverer {
+	return &NopDiscoverer{}
+}
+
+// Discover does nothing and returns empty device details.
+func (d *NopDiscoverer) Discover(ctx context.Context, nn ndddvrv1.Nn) (*ndddvrv1.DeviceDetails, error) {
+	return &ndddvrv1.DeviceDetails{}, nil
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/pkg/discovery/discoverypb"
+	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
+	"github.com/netw-device-driver/ndd-runtime/pkg/event"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	"github.com/netw-device-driver/ndd-runtime/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type fakeDiscoveryServer struct {
+	discoverypb.UnimplementedDiscoveryServer
+
+	details map[string]*discoverypb.DeviceDetails
+}
+
+func (s *fakeDiscoveryServer) Get(ctx context.Context, req *discoverypb.Request) (*discoverypb.DeviceDetails, error) {
+	d, ok := s.details[req.GetNetworkNodeName()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown network node %s", req.GetNetworkNodeName())
+	}
+	return d, nil
+}
+
+// startDiscoveryServer starts the discovery service of a device driver on a
+// bufconn listener and returns the dial option to connect to it.
+func startDiscoveryServer(t *testing.T, details map[string]*discoverypb.DeviceDetails) grpc.DialOption {
+	t.Helper()
+	l := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	discoverypb.RegisterDiscoveryServer(s, &fakeDiscoveryServer{details: details})
+	go s.Serve(l) // nolint:errcheck
+	t.Cleanup(s.Stop)
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return l.Dial()
+	})
+}
+
+// fakeValidator validates every network node with the built-in device driver.
+type fakeValidator struct{}
+
+func (v *fakeValidator) ValidateCredentials(ctx context.Context, namespace, credentialsName, targetAddress string) (*Credentials, error) {
+	return &Credentials{Username: testUsername, Password: testPassword}, nil
+}
+
+func (v *fakeValidator) ValidateProxy(ctx context.Context, namespace, proxy, proxyCredentialsName string) (*Credentials, error) {
+	return nil, nil
+}
+
+func (v *fakeValidator) ValidateTLSCredentials(ctx context.Context, namespace, tlsCredentialsName string) (*TLSCredentials, error) {
+	return nil, nil
+}
+
+func (v *fakeValidator) ValidateReachability(ctx context.Context, nn ndddvrv1.Nn, creds *Credentials, tls *TLSCredentials, handshake bool) (*Reachability, error) {
+	return &Reachability{}, nil
+}
+
+func (v *fakeValidator) SelectDeviceDriver(ctx context.Context, nn ndddvrv1.Nn) (*ndddvrv1.DeviceDriver, error) {
+	return nil, nil
+}
+
+func (v *fakeValidator) ValidateDeviceDriver(ctx context.Context, nn ndddvrv1.Nn, dd *ndddvrv1.DeviceDriver) (*corev1.PodTemplateSpec, error) {
+	return &corev1.PodTemplateSpec{}, nil
+}
+
+func (v *fakeValidator) ValidateShardDeviceDriver(ctx context.Context, dd *ndddvrv1.DeviceDriver, shard string) (*corev1.PodTemplateSpec, error) {
+	return &corev1.PodTemplateSpec{}, nil
+}
+
+func (v *fakeValidator) ValidatePermissions(ctx context.Context, dd *ndddvrv1.DeviceDriver) ([]rbacv1.PolicyRule, error) {
+	return nil, nil
+}
+
+func TestDiscovery(t *testing.T) {
+	dial := startDiscoveryServer(t, map[string]*discoverypb.DeviceDetails{
+		"leaf1": {
+			Type:         "nokia-srl",
+			Hostname:     "leaf1",
+			Kind:         "7220 IXR-D2",
+			SwVersion:    "v21.6.1",
+			MacAddress:   "00:01:02:03:04:05",
+			SerialNumber: "NK1234",
+		},
+	})
+	srl := nddv1.DeviceType("nokia-srl")
+
+	type want struct {
+		details   *ndddvrv1.DeviceDetails
+		condition nddv1.Condition
+		result    reconcile.Result
+	}
+
+	cases := map[string]struct {
+		reason string
+		name   string
+		want   want
+	}{
+		"Discovered": {
+			reason: "The device details reported by the device driver should be recorded and the network node should be discovered.",
+			name:   "leaf1",
+			want: want{
+				details: &ndddvrv1.DeviceDetails{
+					Type:         &srl,
+					HostName:     utils.StringPtr("leaf1"),
+					Kind:         utils.StringPtr("7220 IXR-D2"),
+					SwVersion:    utils.StringPtr("v21.6.1"),
+					MacAddress:   utils.StringPtr("00:01:02:03:04:05"),
+					SerialNumber: utils.StringPtr("NK1234"),
+				},
+				condition: ndddvrv1.Discovered(),
+				result:    reconcile.Result{RequeueAfter: discoveryInterval},
+			},
+		},
+		"NotDiscovered": {
+			reason: "A network node the device driver cannot discover should not be discovered.",
+			name:   "leaf2",
+			want: want{
+				condition: ndddvrv1.NotDiscovered(),
+				result:    reconcile.Result{RequeueAfter: shortWait},
+			},
+		},
+	}
+
+	s := runtime.NewScheme()
+	if err := ndddvrv1.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme(...): %v", err)
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			nn := networkNode(ndddvrv1.DeviceDriverKindGnmi, "10.0.0.1:57400", "")
+			nn.SetName(tc.name)
+			nn.Spec.Target.CredentialsName = utils.StringPtr("creds")
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(nn).Build()
+
+			r := &Reconciler{
+				client:      c,
+				nnFinalizer: resource.NewAPIFinalizer(c, finalizer),
+				hooks:       NewNopHooks(),
+				validator:   &fakeValidator{},
+				discoverer: NewGrpcDiscoverer(logging.NewNopLogger(), ndddvrv1.Namespace,
+					WithDeviceDriverTargetFn(func(nn ndddvrv1.Nn) string { return "bufnet" }),
+					WithDialOptions(dial, grpc.WithInsecure())),
+				log:                 logging.NewNopLogger(),
+				record:              event.NewNopRecorder(),
+				reachabilityBackoff: workqueue.NewItemExponentialFailureRateLimiter(reachabilityBaseBackoff, reachabilityMaxBackoff),
+			}
+
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Name: tc.name}}
+			result, err := r.Reconcile(context.Background(), req)
+			if err != nil {
+				t.Fatalf("\n%s\nReconcile(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.result, result); diff != "" {
+				t.Errorf("\n%s\nReconcile(...): -want result, +got result:\n%s", tc.reason, diff)
+			}
+
+			got := &ndddvrv1.NetworkNode{}
+			if err := c.Get(context.Background(), req.NamespacedName, got); err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.details, got.Status.DeviceDetails); diff != "" {
+				t.Errorf("\n%s\nReconcile(...): -want device details, +got device details:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.condition, got.GetCondition(ndddvrv1.ConditionKindDeviceDriverReady), cmpopts.IgnoreFields(nddv1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("\n%s\nReconcile(...): -want condition, +got condition:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(ndddvrv1.Healthy(), got.GetCondition(ndddvrv1.ConditionKindDeviceDriverHealthy), cmpopts.IgnoreFields(nddv1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("\n%s\nReconcile(...): -want health, +got health:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
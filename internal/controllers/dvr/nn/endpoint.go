@@ -0,0 +1,53 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"strings"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/pki"
+)
+
+// buildEndpoint builds the endpoint of the device driver serving the network
+// node, which is the service of the network node. The server name and the
+// certificate authority are only published when the device driver serves tls.
+// The endpoint is not ready until the device driver is healthy.
+func buildEndpoint(nn ndddvrv1.Nn, namespace string, serverTLS bool) *ndddvrv1.DeviceDriverEndpoint {
+	service := strings.Join([]string{ndddvrv1.PrefixService, nn.GetName()}, "-")
+	host := ndddvrv1.ServiceDNSName(service, namespace)
+	e := &ndddvrv1.DeviceDriverEndpoint{
+		Host: host,
+		Port: nn.GetGrpcServerPort(),
+	}
+	if serverTLS {
+		e.ServerName = host
+		e.CA = &ndddvrv1.CAReference{
+			Namespace: namespace,
+			Name:      pki.ConfigMapCA,
+			Key:       pki.KeyCACert,
+		}
+	}
+	return e
+}
+
+// setEndpointReady sets the readiness of the endpoint of the network node.
+func setEndpointReady(nn ndddvrv1.Nn, ready bool) {
+	if e := nn.GetDeviceDriverEndpoint(); e != nil {
+		e.Ready = ready
+	}
+}
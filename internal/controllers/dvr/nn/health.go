@@ -0,0 +1,115 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"strings"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podFailureReasons are the reasons of waiting containers which indicate the
+// device driver pod fails rather than starts.
+var podFailureReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CrashLoopBackOff":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// Health returns the health of the device driver deployment of the network
+// node. The device driver is healthy once the deployment rolled out and is
+// available, failures of the device driver pods are reported with their reason.
+func (h *DeviceDriverHooks) Health(ctx context.Context, nn ndddvrv1.Nn) (nddv1.Condition, error) {
+	// the deployment of the network node, or of its shard, is the controller of
+	// the network node
+	d := &appsv1.Deployment{}
+	name := nn.GetControllerReference().Name
+	if name == "" {
+		name = strings.Join([]string{ndddvrv1.PrefixDeployment, nn.GetName()}, "-")
+	}
+	if err := h.client.Get(ctx, types.NamespacedName{Namespace: h.namespace, Name: name}, d); err != nil {
+		return ndddvrv1.UnknownHealth(), errors.Wrap(err, errGetDeployment)
+	}
+
+	// the deployment only reports pod failures once the progress deadline is
+	// exceeded, so the pods are inspected first
+	pods := &corev1.PodList{}
+	if err := h.client.List(ctx, pods,
+		client.InNamespace(h.namespace),
+		client.MatchingLabels(d.Spec.Selector.MatchLabels),
+	); err != nil {
+		return ndddvrv1.UnknownHealth(), errors.Wrap(err, errListPods)
+	}
+	for _, p := range pods.Items {
+		if c, failed := podFailure(p); failed {
+			return c, nil
+		}
+	}
+
+	if d.Status.ObservedGeneration < d.GetGeneration() {
+		return ndddvrv1.DeviceDriverDeploying(), nil
+	}
+	for _, c := range d.Status.Conditions {
+		switch {
+		case c.Type == appsv1.DeploymentReplicaFailure && c.Status == corev1.ConditionTrue:
+			return ndddvrv1.Unhealthy().WithMessage(errUnavailableDeployment + ": " + c.Message), nil
+		case c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse:
+			return ndddvrv1.Unhealthy().WithMessage(errUnavailableDeployment + ": " + c.Message), nil
+		}
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionTrue && d.Status.UpdatedReplicas == d.Status.Replicas {
+			return ndddvrv1.Healthy(), nil
+		}
+	}
+	return ndddvrv1.DeviceDriverDeploying(), nil
+}
+
+// podFailure returns the condition of a failing device driver pod.
+func podFailure(p corev1.Pod) (nddv1.Condition, bool) {
+	if p.Status.Phase == corev1.PodFailed {
+		reason := p.Status.Reason
+		if reason == "" {
+			reason = string(corev1.PodFailed)
+		}
+		return ndddvrv1.DeviceDriverPodFailure(reason).WithMessage("pod " + p.GetName() + ": " + p.Status.Message), true
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, p.Status.InitContainerStatuses...), p.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if w := cs.State.Waiting; w != nil && podFailureReasons[w.Reason] {
+			return ndddvrv1.DeviceDriverPodFailure(w.Reason).WithMessage("container " + cs.Name + ": " + w.Message), true
+		}
+		if t := cs.State.Terminated; t != nil && t.Reason == "OOMKilled" {
+			return ndddvrv1.DeviceDriverPodFailure(t.Reason).WithMessage("container " + cs.Name + " was killed because it ran out of memory"), true
+		}
+		if t := cs.LastTerminationState.Terminated; t != nil && t.Reason == "OOMKilled" && !cs.Ready {
+			return ndddvrv1.DeviceDriverPodFailure(t.Reason).WithMessage("container " + cs.Name + " was killed because it ran out of memory"), true
+		}
+	}
+	return nddv1.Condition{}, false
+}
@@ -18,15 +18,26 @@ package nn
 
 import (
 	"context"
+	"reflect"
+	"strings"
 
 	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/pki"
 	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
 
 	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/meta"
 	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	"github.com/netw-device-driver/ndd-runtime/pkg/utils"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -38,21 +49,52 @@ const (
 	errDeleteConfigMap          = "cannot delete device driver config map"
 	errDeleteService            = "cannot delete device driver service"
 	errDeleteClusterRoleBinding = "cannot delete device driver cluster role binding"
+	errDeleteClusterRole        = "cannot delete device driver cluster role"
+	errDeleteRoleBinding        = "cannot delete device driver role binding"
+	errDeleteRole               = "cannot delete device driver role"
+	errDeleteNetworkPolicy      = "cannot delete device driver network policy"
+	errDeleteTLSSecret          = "cannot delete device driver tls secret"
+	errDeleteCredentialsSecret  = "cannot delete device driver credentials secret"
 	errApplyDeployment          = "cannot apply device driver deployment"
 	errApplyServiceAccount      = "cannot apply device driver service account"
 	errApplyConfigMap           = "cannot apply device driver config map"
+	errBuildConfigMap           = "cannot build device driver config map"
 	errApplyService             = "cannot apply device driver service"
 	errAppyClusterRoleBinding   = "cannot apply device driver cluster role binding"
+	errApplyClusterRole         = "cannot apply device driver cluster role"
+	errApplyRoleBinding         = "cannot apply device driver role binding"
+	errApplyRole                = "cannot apply device driver role"
+	errApplyNetworkPolicy       = "cannot apply device driver network policy"
+	errApplyTLSSecret           = "cannot apply device driver tls secret"
+	errApplyCredentialsSecret   = "cannot apply device driver credentials secret"
 	errUnavailableDeployment    = "device driver deployment is unavailable"
+	errGetDeployment            = "cannot get device driver deployment"
+	errGetService               = "cannot get device driver service"
+	errScaleDeployment          = "cannot scale device driver deployment"
+	errServedByShard            = "network node is still served by shard"
+	errListPods                 = "cannot list device driver pods"
 )
 
 // A Hooks performs operations to deploy the device driver for the network node.
 type Hooks interface {
-	// Deploy performs operations to deploy the device driver for the network node
-	Deploy(ctx context.Context, nn ndddvrv1.Nn, c *corev1.Container) error
+	// Deploy performs operations to deploy the device driver for the network
+	// node and returns true when the device driver is rolled out
+	Deploy(ctx context.Context, nn ndddvrv1.Nn, pt *corev1.PodTemplateSpec, permissions []rbacv1.PolicyRule, networkPolicy bool, creds *Credentials, tls *TLSCredentials) (bool, error)
+
+	// DeployShard performs operations to serve the network node from the shard
+	// of a sharded device driver and returns true when the network node is
+	// rolled out
+	DeployShard(ctx context.Context, nn ndddvrv1.Nn, shard string, port int, creds *Credentials, tls *TLSCredentials) (bool, error)
 
 	// Destroy performs operations to destroy the device driver for the network node
-	Destroy(ctx context.Context, nn ndddvrv1.Nn, c *corev1.Container) error
+	Destroy(ctx context.Context, nn ndddvrv1.Nn, pt *corev1.PodTemplateSpec, creds *Credentials, tls *TLSCredentials) error
+
+	// Suspend scales the device driver for the network node to zero or takes
+	// the network node out of its shard
+	Suspend(ctx context.Context, nn ndddvrv1.Nn) error
+
+	// Health returns the health of the deployed device driver for the network node
+	Health(ctx context.Context, nn ndddvrv1.Nn) (nddv1.Condition, error)
 }
 
 // DeviceDriverHooks performs operations to deploy the device driver.
@@ -71,84 +113,390 @@ func NewDeviceDriverHooks(client resource.ClientApplicator, log logging.Logger,
 	}
 }
 
-// Deploy performs operations to deploy the device driver for the network node
-func (h *DeviceDriverHooks) Deploy(ctx context.Context, nn ndddvrv1.Nn, c *corev1.Container) error {
-	cm := buildConfigMap(nn, h.namespace)
-	if err := h.client.Apply(ctx, cm); err != nil {
-		return errors.Wrap(err, errApplyConfigMap)
+// Deploy performs operations to deploy the device driver for the network node.
+// The objects of the device driver are applied when they are missing or differ
+// from the effective device driver configuration. The deployment is only
+// applied when the effective device driver configuration differs from the
+// configuration of the deployed device driver, Deploy returns true when the
+// device driver is rolled out. The grpc server of the device
+// driver is served with a certificate of the certificate authority of the core
+// when the core runs one.
+func (h *DeviceDriverHooks) Deploy(ctx context.Context, nn ndddvrv1.Nn, pt *corev1.PodTemplateSpec, permissions []rbacv1.PolicyRule, networkPolicy bool, creds *Credentials, tls *TLSCredentials) (bool, error) {
+	ca, err := pki.LoadCA(ctx, h.client, h.namespace)
+	if err != nil {
+		return false, err
+	}
+	var sc *corev1.Secret
+	if ca != nil {
+		service := strings.Join([]string{ndddvrv1.PrefixService, nn.GetName()}, "-")
+		if sc, err = buildServerCertSecret(ctx, h.client, ca, serverCertObjectMeta(nn, h.namespace), service, []string{service}); err != nil {
+			return false, err
+		}
+	}
+	cm, err := buildConfigMap(nn, h.namespace, sc != nil)
+	if err != nil {
+		return false, errors.Wrap(err, errBuildConfigMap)
 	}
-
 	s := buildService(nn, h.namespace)
-	if err := h.client.Apply(ctx, s); err != nil {
-		return errors.Wrap(err, errApplyService)
+	sa := buildServiceAccount(nn, h.namespace)
+	cs := buildCredentialsSecret(nn, h.namespace, creds)
+	ts := buildTLSSecret(nn, h.namespace, tls)
+	cr := buildClusterRole(nn, permissions)
+	crb := buildClusterRoleBinding(nn, h.namespace)
+	r := buildRole(nn, h.namespace)
+	rb := buildRoleBinding(nn, h.namespace)
+	er := buildEventsRole(nnObjectMeta(nn, h.namespace), h.namespace)
+	erb := buildEventsRoleBinding(nnObjectMeta(nn, h.namespace), h.namespace)
+	podAnnotations := map[string]string{
+		ndddvrv1.AnnotationConfigHash: configHash(cm),
+		ndddvrv1.AnnotationSecretHash: secretHash(cs, ts, sc),
+		ndddvrv1.AnnotationRBACHash:   rbacHash(cr, r),
+	}
+	var np *networkingv1.NetworkPolicy
+	if networkPolicy {
+		apiServer, err := apiServerEgress(ctx, h.client)
+		if err != nil {
+			return false, err
+		}
+		np = buildNetworkPolicy(nn, apiServer, h.namespace)
+		podAnnotations[ndddvrv1.AnnotationPolicyHash] = policyHash(np)
 	}
+	d := buildDeployment(nn, pt, h.namespace, sc != nil, podAnnotations)
 
-	sa := buildServiceAccount(nn, h.namespace)
-	if err := h.client.Apply(ctx, sa); err != nil {
-		return errors.Wrap(err, errApplyServiceAccount)
+	hash := specHash(d, s)
+	nn.SetEffectiveSpecHash(hash)
+	nn.SetControllerReference(nddv1.Reference{Name: d.GetName()})
+	nn.SetDeviceDriverEndpoint(buildEndpoint(nn, h.namespace, sc != nil))
+
+	if err := applyIfChanged(ctx, h.client, cm); err != nil {
+		return false, errors.Wrap(err, errApplyConfigMap)
 	}
 
-	d := buildDeployment(nn, c, h.namespace)
+	if err := applyIfChanged(ctx, h.client, s); err != nil {
+		return false, errors.Wrap(err, errApplyService)
+	}
+
+	if np != nil {
+		if err := applyIfChanged(ctx, h.client, np); err != nil {
+			return false, errors.Wrap(err, errApplyNetworkPolicy)
+		}
+	} else {
+		if err := h.client.Delete(ctx, emptyNetworkPolicy(nn, h.namespace)); resource.IgnoreNotFound(err) != nil {
+			return false, errors.Wrap(err, errDeleteNetworkPolicy)
+		}
+	}
+
+	if err := applyIfChanged(ctx, h.client, sa); err != nil {
+		return false, errors.Wrap(err, errApplyServiceAccount)
+	}
+
+	// the device driver is only granted access to its own objects and the
+	// permissions of the device driver
+	if err := applyIfChanged(ctx, h.client, r); err != nil {
+		return false, errors.Wrap(err, errApplyRole)
+	}
+
+	if err := applyIfChanged(ctx, h.client, rb); err != nil {
+		return false, errors.Wrap(err, errApplyRoleBinding)
+	}
+
+	// the events of the cluster scoped network node are recorded in the
+	// default namespace
+	if er != nil {
+		if err := applyIfChanged(ctx, h.client, er); err != nil {
+			return false, errors.Wrap(err, errApplyRole)
+		}
+		if err := applyIfChanged(ctx, h.client, erb); err != nil {
+			return false, errors.Wrap(err, errApplyRoleBinding)
+		}
+	}
+
+	if err := applyIfChanged(ctx, h.client, cr); err != nil {
+		return false, errors.Wrap(err, errApplyClusterRole)
+	}
+
+	changed, err := objectChanged(ctx, h.client, crb)
+	if err != nil {
+		return false, errors.Wrap(err, errAppyClusterRoleBinding)
+	}
+	if changed {
+		if err := applyClusterRoleBinding(ctx, h.client, crb); err != nil {
+			return false, errors.Wrap(err, errAppyClusterRoleBinding)
+		}
+	}
+
+	if err := applyIfChanged(ctx, h.client, cs); err != nil {
+		return false, errors.Wrap(err, errApplyCredentialsSecret)
+	}
+
+	if tls != nil {
+		if err := applyIfChanged(ctx, h.client, ts); err != nil {
+			return false, errors.Wrap(err, errApplyTLSSecret)
+		}
+	} else {
+		// the network node no longer uses tls credentials
+		if err := h.client.Delete(ctx, ts); resource.IgnoreNotFound(err) != nil {
+			return false, errors.Wrap(err, errDeleteTLSSecret)
+		}
+	}
+
+	if sc != nil {
+		if err := applyIfChanged(ctx, h.client, sc); err != nil {
+			return false, errors.Wrap(err, errApplyServerCert)
+		}
+	} else {
+		// the core no longer runs a certificate authority
+		if err := h.client.Delete(ctx, emptyServerCertSecret(nn, h.namespace)); resource.IgnoreNotFound(err) != nil {
+			return false, errors.Wrap(err, errDeleteServerCert)
+		}
+	}
+
+	// the deployment records the hash of the configuration it was rolled out
+	// with, the deployment is only applied when the hash changes such that the
+	// device driver is not rolled out on every reconcile
+	current := &appsv1.Deployment{}
+	err = h.client.Get(ctx, types.NamespacedName{Namespace: d.GetNamespace(), Name: d.GetName()}, current)
+	if resource.IgnoreNotFound(err) != nil {
+		return false, errors.Wrap(err, errGetDeployment)
+	}
+	if err == nil && current.GetAnnotations()[ndddvrv1.AnnotationSpecHash] == hash {
+		return false, nil
+	}
+	meta.AddAnnotations(d, map[string]string{ndddvrv1.AnnotationSpecHash: hash})
+
 	if err := h.client.Apply(ctx, d); err != nil {
-		return errors.Wrap(err, errApplyDeployment)
+		return false, errors.Wrap(err, errApplyDeployment)
 	}
+	return true, nil
+}
 
-	b := buildClusterRoleBinding(nn, h.namespace)
-	if err := h.client.Apply(ctx, b); err != nil {
-		return errors.Wrap(err, errAppyClusterRoleBinding)
+// DeployShard performs operations to serve the network node from the shard of
+// a sharded device driver. The device driver objects of the network node are
+// replaced by the secrets and the service of the network node, the service
+// selects the pods of the shard. The secrets and the service are applied when
+// they are missing or differ, the device driver objects of the network node are
+// deleted when the hash of the configuration changes. The shard itself is
+// deployed by the device driver controller.
+func (h *DeviceDriverHooks) DeployShard(ctx context.Context, nn ndddvrv1.Nn, shard string, port int, creds *Credentials, tls *TLSCredentials) (bool, error) {
+	// the shard serves tls when the core runs a certificate authority
+	ca, err := pki.LoadCA(ctx, h.client, h.namespace)
+	if err != nil {
+		return false, err
 	}
-	nn.SetControllerReference(nddv1.Reference{Name: d.GetName()})
+	s := buildShardService(nn, shard, port, h.namespace)
+	cs := buildCredentialsSecret(nn, h.namespace, creds)
+	ts := buildTLSSecret(nn, h.namespace, tls)
 
-	for _, c := range d.Status.Conditions {
-		if c.Type == appsv1.DeploymentAvailable {
-			if c.Status == corev1.ConditionTrue {
-				return nil
-			}
-			return errors.Errorf("%s: %s", errUnavailableDeployment, c.Message)
+	hash := specHash(&appsv1.Deployment{}, s) + secretHash(cs, ts)
+	nn.SetEffectiveSpecHash(hash)
+	nn.SetControllerReference(nddv1.Reference{Name: shard})
+	nn.SetDeviceDriverEndpoint(buildEndpoint(nn, h.namespace, ca != nil))
+
+	if err := applyIfChanged(ctx, h.client, cs); err != nil {
+		return false, errors.Wrap(err, errApplyCredentialsSecret)
+	}
+	if tls != nil {
+		if err := applyIfChanged(ctx, h.client, ts); err != nil {
+			return false, errors.Wrap(err, errApplyTLSSecret)
+		}
+	} else {
+		if err := h.client.Delete(ctx, ts); resource.IgnoreNotFound(err) != nil {
+			return false, errors.Wrap(err, errDeleteTLSSecret)
 		}
 	}
-	return nil
+
+	// the service records the hash of the configuration the network node was
+	// switched to the shard with, the device driver objects of the network
+	// node are only deleted when the hash changes
+	current := &corev1.Service{}
+	err = h.client.Get(ctx, types.NamespacedName{Namespace: s.GetNamespace(), Name: s.GetName()}, current)
+	if resource.IgnoreNotFound(err) != nil {
+		return false, errors.Wrap(err, errGetService)
+	}
+	meta.AddAnnotations(s, map[string]string{ndddvrv1.AnnotationSpecHash: hash})
+	if err == nil && current.GetAnnotations()[ndddvrv1.AnnotationSpecHash] == hash {
+		return false, errors.Wrap(applyIfChanged(ctx, h.client, s), errApplyService)
+	}
+
+	// the network node is no longer served by its own device driver
+	cm, err := buildConfigMap(nn, h.namespace, false)
+	if err != nil {
+		return false, errors.Wrap(err, errBuildConfigMap)
+	}
+	if err := h.client.Delete(ctx, buildDeployment(nn, &corev1.PodTemplateSpec{}, h.namespace, false, nil)); resource.IgnoreNotFound(err) != nil {
+		return false, errors.Wrap(err, errDeleteDeployment)
+	}
+	if err := h.client.Delete(ctx, cm); resource.IgnoreNotFound(err) != nil {
+		return false, errors.Wrap(err, errDeleteConfigMap)
+	}
+	if err := h.client.Delete(ctx, buildServiceAccount(nn, h.namespace)); resource.IgnoreNotFound(err) != nil {
+		return false, errors.Wrap(err, errDeleteServiceAccount)
+	}
+	if err := h.client.Delete(ctx, buildClusterRoleBinding(nn, h.namespace)); resource.IgnoreNotFound(err) != nil {
+		return false, errors.Wrap(err, errDeleteClusterRoleBinding)
+	}
+	if err := h.client.Delete(ctx, buildClusterRole(nn, nil)); resource.IgnoreNotFound(err) != nil {
+		return false, errors.Wrap(err, errDeleteClusterRole)
+	}
+	if err := h.client.Delete(ctx, buildRoleBinding(nn, h.namespace)); resource.IgnoreNotFound(err) != nil {
+		return false, errors.Wrap(err, errDeleteRoleBinding)
+	}
+	if err := h.client.Delete(ctx, buildRole(nn, h.namespace)); resource.IgnoreNotFound(err) != nil {
+		return false, errors.Wrap(err, errDeleteRole)
+	}
+	if err := deleteEventsRole(ctx, h.client, nnObjectMeta(nn, h.namespace), h.namespace); err != nil {
+		return false, err
+	}
+	if err := h.client.Delete(ctx, emptyNetworkPolicy(nn, h.namespace)); resource.IgnoreNotFound(err) != nil {
+		return false, errors.Wrap(err, errDeleteNetworkPolicy)
+	}
+	if err := h.client.Delete(ctx, emptyServerCertSecret(nn, h.namespace)); resource.IgnoreNotFound(err) != nil {
+		return false, errors.Wrap(err, errDeleteServerCert)
+	}
+
+	if err := h.client.Apply(ctx, s); err != nil {
+		return false, errors.Wrap(err, errApplyService)
+	}
+	return true, nil
 }
 
 // Destroy performs operations to destroy the device driver for the network node
-func (h *DeviceDriverHooks) Destroy(ctx context.Context, nn ndddvrv1.Nn, c *corev1.Container) error {
-	cm := buildConfigMap(nn, h.namespace)
-	if err := h.client.Delete(ctx, cm); err != nil {
+func (h *DeviceDriverHooks) Destroy(ctx context.Context, nn ndddvrv1.Nn, pt *corev1.PodTemplateSpec, creds *Credentials, tls *TLSCredentials) error {
+	cm, err := buildConfigMap(nn, h.namespace, false)
+	if err != nil {
+		return errors.Wrap(err, errBuildConfigMap)
+	}
+	if err := h.client.Delete(ctx, cm); resource.IgnoreNotFound(err) != nil {
 		return errors.Wrap(err, errDeleteConfigMap)
 	}
 
 	s := buildService(nn, h.namespace)
-	if err := h.client.Delete(ctx, s); err != nil {
+	if err := h.client.Delete(ctx, s); resource.IgnoreNotFound(err) != nil {
 		return errors.Wrap(err, errDeleteService)
 	}
 
+	np := emptyNetworkPolicy(nn, h.namespace)
+	if err := h.client.Delete(ctx, np); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errDeleteNetworkPolicy)
+	}
+
 	sa := buildServiceAccount(nn, h.namespace)
-	if err := h.client.Delete(ctx, sa); err != nil {
+	if err := h.client.Delete(ctx, sa); resource.IgnoreNotFound(err) != nil {
 		return errors.Wrap(err, errDeleteServiceAccount)
 	}
 
-	d := buildDeployment(nn, c, h.namespace)
-	if err := h.client.Delete(ctx, d); err != nil {
+	cs := buildCredentialsSecret(nn, h.namespace, creds)
+	if err := h.client.Delete(ctx, cs); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errDeleteCredentialsSecret)
+	}
+
+	ts := buildTLSSecret(nn, h.namespace, tls)
+	if err := h.client.Delete(ctx, ts); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errDeleteTLSSecret)
+	}
+
+	sc := emptyServerCertSecret(nn, h.namespace)
+	if err := h.client.Delete(ctx, sc); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errDeleteServerCert)
+	}
+
+	d := buildDeployment(nn, pt, h.namespace, false, nil)
+	if err := h.client.Delete(ctx, d); resource.IgnoreNotFound(err) != nil {
 		return errors.Wrap(err, errDeleteDeployment)
 	}
 
 	b := buildClusterRoleBinding(nn, h.namespace)
-	if err := h.client.Delete(ctx, b); err != nil {
+	if err := h.client.Delete(ctx, b); resource.IgnoreNotFound(err) != nil {
 		return errors.Wrap(err, errDeleteClusterRoleBinding)
 	}
-	nn.SetControllerReference(nddv1.Reference{Name: d.GetName()})
 
-	for _, c := range d.Status.Conditions {
-		if c.Type == appsv1.DeploymentAvailable {
-			if c.Status == corev1.ConditionTrue {
-				return nil
-			}
-			return errors.Errorf("%s: %s", errUnavailableDeployment, c.Message)
-		}
+	cr := buildClusterRole(nn, nil)
+	if err := h.client.Delete(ctx, cr); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errDeleteClusterRole)
 	}
+
+	rb := buildRoleBinding(nn, h.namespace)
+	if err := h.client.Delete(ctx, rb); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errDeleteRoleBinding)
+	}
+
+	r := buildRole(nn, h.namespace)
+	if err := h.client.Delete(ctx, r); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errDeleteRole)
+	}
+
+	if err := deleteEventsRole(ctx, h.client, nnObjectMeta(nn, h.namespace), h.namespace); err != nil {
+		return err
+	}
+	nn.SetControllerReference(nddv1.Reference{})
+	nn.SetDeviceDriverEndpoint(nil)
+	nn.SetEffectiveSpecHash("")
 	return nil
 }
 
+// Suspend scales the device driver deployment for the network node to zero
+// and keeps the other objects, such that Deploy restores the device driver. The
+// hash of the deployment is removed since the deployment no longer matches its
+// configuration. A network node served by a shard is taken out of the shard by
+// the device driver controller, which renders the shards without the network
+// nodes in maintenance; Suspend returns an error until the network node is no
+// longer assigned to the shard and deletes the service of the network node,
+// such that the providers no longer reach the shard for the network node.
+func (h *DeviceDriverHooks) Suspend(ctx context.Context, nn ndddvrv1.Nn) error {
+	if shard := nn.GetAnnotations()[ndddvrv1.AnnotationShard]; shard != "" {
+		return errors.New(errServedByShard + ": " + shard)
+	}
+	name := strings.Join([]string{ndddvrv1.PrefixDeployment, nn.GetName()}, "-")
+	if ref := nn.GetControllerReference().Name; ref != "" && ref != name {
+		if err := h.client.Delete(ctx, buildService(nn, h.namespace)); resource.IgnoreNotFound(err) != nil {
+			return errors.Wrap(err, errDeleteService)
+		}
+		// DeployShard serves the network node from its shard again when
+		// the network node leaves maintenance
+		nn.SetControllerReference(nddv1.Reference{})
+		nn.SetDeviceDriverEndpoint(nil)
+		nn.SetEffectiveSpecHash("")
+		return nil
+	}
+	d := &appsv1.Deployment{}
+	if err := h.client.Get(ctx, types.NamespacedName{Namespace: h.namespace, Name: name}, d); err != nil {
+		return errors.Wrap(resource.IgnoreNotFound(err), errGetDeployment)
+	}
+	if d.Spec.Replicas != nil && *d.Spec.Replicas == 0 {
+		return nil
+	}
+	d.Spec.Replicas = utils.Int32Ptr(0)
+	meta.RemoveAnnotations(d, ndddvrv1.AnnotationSpecHash)
+	return errors.Wrap(h.client.Update(ctx, d), errScaleDeployment)
+}
+
+// objectChanged returns true when the object does not exist or when the
+// current object differs from it. The fields the object does not set, e.g. the
+// fields defaulted by the api server, are not compared.
+func objectChanged(ctx context.Context, c resource.ClientApplicator, o client.Object) (bool, error) {
+	current := reflect.New(reflect.TypeOf(o).Elem()).Interface().(client.Object)
+	err := c.Get(ctx, types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}, current)
+	if kerrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !equality.Semantic.DeepDerivative(o, current), nil
+}
+
+// applyIfChanged applies the object when it does not exist or when the current
+// object differs from it, such that the objects that are changed or deleted out
+// of band are repaired without updating the unchanged objects on every
+// reconcile.
+func applyIfChanged(ctx context.Context, c resource.ClientApplicator, o client.Object) error {
+	changed, err := objectChanged(ctx, c, o)
+	if err != nil || !changed {
+		return err
+	}
+	return c.Apply(ctx, o)
+}
+
 // NopHooks performs no operations.
 type NopHooks struct{}
 
@@ -157,12 +505,27 @@ func NewNopHooks() *NopHooks {
 	return &NopHooks{}
 }
 
-// Deploy does nothing and returns nil.
-func (h *NopHooks) Deploy(ctx context.Context, nn ndddvrv1.Nn, c *corev1.Container) error {
-	return nil
+// Deploy does nothing and returns false.
+func (h *NopHooks) Deploy(ctx context.Context, nn ndddvrv1.Nn, pt *corev1.PodTemplateSpec, permissions []rbacv1.PolicyRule, networkPolicy bool, creds *Credentials, tls *TLSCredentials) (bool, error) {
+	return false, nil
+}
+
+// DeployShard does nothing and returns false.
+func (h *NopHooks) DeployShard(ctx context.Context, nn ndddvrv1.Nn, shard string, port int, creds *Credentials, tls *TLSCredentials) (bool, error) {
+	return false, nil
 }
 
 // Destroy does nothing and returns nil.
-func (h *NopHooks) Destroy(ctx context.Context, nn ndddvrv1.Nn, c *corev1.Container) error {
+func (h *NopHooks) Destroy(ctx context.Context, nn ndddvrv1.Nn, pt *corev1.PodTemplateSpec, creds *Credentials, tls *TLSCredentials) error {
 	return nil
 }
+
+// Suspend does nothing and returns nil.
+func (h *NopHooks) Suspend(ctx context.Context, nn ndddvrv1.Nn) error {
+	return nil
+}
+
+// Health returns healthy and does nothing else.
+func (h *NopHooks) Health(ctx context.Context, nn ndddvrv1.Nn) (nddv1.Condition, error) {
+	return ndddvrv1.Healthy(), nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDeployShard(t *testing.T) {
+	key := types.NamespacedName{Namespace: ndddvrv1.Namespace, Name: strings.Join([]string{ndddvrv1.PrefixService, "nn1"}, "-")}
+	creds := &Credentials{Username: testUsername, Password: testPassword}
+
+	type want struct {
+		switched bool
+	}
+	cases := map[string]struct {
+		reason string
+		mutate func(ctx context.Context, c client.Client) error
+		want   want
+	}{
+		"Unchanged": {
+			reason: "A network node served by an unchanged shard should not be switched again.",
+			mutate: func(ctx context.Context, c client.Client) error { return nil },
+			want:   want{switched: false},
+		},
+		"ServiceChanged": {
+			reason: "A service of the network node that is changed out of band should be repaired without switching the network node again.",
+			mutate: func(ctx context.Context, c client.Client) error {
+				s := &corev1.Service{}
+				if err := c.Get(ctx, key, s); err != nil {
+					return err
+				}
+				s.Spec.Selector = map[string]string{ndddvrv1.LabelApplication: "other"}
+				return c.Update(ctx, s)
+			},
+			want: want{switched: false},
+		},
+		"ServiceDeleted": {
+			reason: "A service of the network node that is deleted out of band should be restored.",
+			mutate: func(ctx context.Context, c client.Client) error {
+				return c.Delete(ctx, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}})
+			},
+			want: want{switched: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			nn := networkNode(ndddvrv1.DeviceDriverKindGnmi, "10.0.0.1:57400", "")
+			c := newFakeClientApplicator(t)
+			h := NewDeviceDriverHooks(c, logging.NewNopLogger(), ndddvrv1.Namespace)
+
+			if _, err := h.DeployShard(ctx, nn, "shard", 9999, creds, nil); err != nil {
+				t.Fatalf("DeployShard(...): %v", err)
+			}
+			want := &corev1.Service{}
+			if err := c.Get(ctx, key, want); err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+
+			if err := tc.mutate(ctx, c); err != nil {
+				t.Fatalf("mutate(...): %v", err)
+			}
+			switched, err := h.DeployShard(ctx, nn, "shard", 9999, creds, nil)
+			if err != nil {
+				t.Fatalf("\n%s\nDeployShard(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.switched, switched); diff != "" {
+				t.Errorf("\n%s\nDeployShard(...): -want switched, +got switched:\n%s", tc.reason, diff)
+			}
+
+			got := &corev1.Service{}
+			if err := c.Get(ctx, key, got); err != nil {
+				t.Fatalf("\n%s\nGet(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(want, got, ignoreResourceVersion); diff != "" {
+				t.Errorf("\n%s\nDeployShard(...): -want service, +got service:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSuspend(t *testing.T) {
+	deployment := types.NamespacedName{Namespace: ndddvrv1.Namespace, Name: strings.Join([]string{ndddvrv1.PrefixDeployment, "nn1"}, "-")}
+	service := types.NamespacedName{Namespace: ndddvrv1.Namespace, Name: strings.Join([]string{ndddvrv1.PrefixService, "nn1"}, "-")}
+
+	type args struct {
+		annotations map[string]string
+		reference   string
+		objects     []client.Object
+	}
+	type want struct {
+		err       string
+		replicas  *int32
+		service   bool
+		reference string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"DeviceDriver": {
+			reason: "The device driver deployment of the network node should be scaled to zero.",
+			args: args{
+				reference: deployment.Name,
+				objects: []client.Object{
+					&appsv1.Deployment{
+						ObjectMeta: metav1.ObjectMeta{Namespace: deployment.Namespace, Name: deployment.Name},
+						Spec:       appsv1.DeploymentSpec{Replicas: utils.Int32Ptr(1)},
+					},
+					&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: service.Namespace, Name: service.Name}},
+				},
+			},
+			want: want{
+				replicas:  utils.Int32Ptr(0),
+				service:   true,
+				reference: deployment.Name,
+			},
+		},
+		"ShardAssigned": {
+			reason: "A network node that is still assigned to its shard should not be suspended.",
+			args: args{
+				annotations: map[string]string{ndddvrv1.AnnotationShard: "shard"},
+				reference:   "shard",
+				objects: []client.Object{
+					&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: service.Namespace, Name: service.Name}},
+				},
+			},
+			want: want{
+				err:       errServedByShard + ": shard",
+				service:   true,
+				reference: "shard",
+			},
+		},
+		"ShardReleased": {
+			reason: "A network node taken out of its shard should no longer be reachable through its service.",
+			args: args{
+				reference: "shard",
+				objects: []client.Object{
+					&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: service.Namespace, Name: service.Name}},
+				},
+			},
+			want: want{
+				service:   false,
+				reference: "",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			nn := networkNode(ndddvrv1.DeviceDriverKindGnmi, "10.0.0.1:57400", "")
+			nn.SetAnnotations(tc.args.annotations)
+			nn.SetControllerReference(nddv1.Reference{Name: tc.args.reference})
+			c := newFakeClientApplicator(t, tc.args.objects...)
+			h := NewDeviceDriverHooks(c, logging.NewNopLogger(), ndddvrv1.Namespace)
+
+			got := ""
+			if err := h.Suspend(ctx, nn); err != nil {
+				got = err.Error()
+			}
+			if diff := cmp.Diff(tc.want.err, got); diff != "" {
+				t.Errorf("\n%s\nSuspend(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reference, nn.GetControllerReference().Name); diff != "" {
+				t.Errorf("\n%s\nSuspend(...): -want controller reference, +got controller reference:\n%s", tc.reason, diff)
+			}
+
+			d := &appsv1.Deployment{}
+			if err := c.Get(ctx, deployment, d); err == nil {
+				if diff := cmp.Diff(tc.want.replicas, d.Spec.Replicas); diff != "" {
+					t.Errorf("\n%s\nSuspend(...): -want replicas, +got replicas:\n%s", tc.reason, diff)
+				}
+			}
+			err := c.Get(ctx, service, &corev1.Service{})
+			if diff := cmp.Diff(tc.want.service, !kerrors.IsNotFound(err)); diff != "" {
+				t.Errorf("\n%s\nSuspend(...): -want service, +got service:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
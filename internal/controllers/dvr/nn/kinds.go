@@ -0,0 +1,150 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// KindsConfigMap is the name of the configmap in the namespace of the core
+	// that extends the device driver kinds. Every key of the configmap is a
+	// device driver kind holding the yaml encoded KindDefaults of the kind,
+	// which take precedence over the built-in defaults of the kind.
+	KindsConfigMap = "ndd-device-driver-kinds"
+
+	// Errors
+	errGetKindsConfigMap       = "cannot get device driver kinds configmap"
+	errParseKindDefaults       = "cannot parse device driver kind defaults"
+	errUnknownDeviceDriverKind = "unknown device driver kind"
+)
+
+// KindDefaults holds the defaults of the device driver container of a device
+// driver kind, used when no device driver is selected for the network node.
+type KindDefaults struct {
+	// Image of the device driver
+	Image string `json:"image"`
+
+	// ImagePullPolicy of the device driver
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Command of the device driver
+	Command []string `json:"command,omitempty"`
+
+	// Args of the device driver, rendered as templates
+	Args []string `json:"args,omitempty"`
+
+	// Resources of the device driver
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// defaultResources are the resources of the built-in device driver kinds.
+var defaultResources = corev1.ResourceRequirements{
+	Requests: corev1.ResourceList{
+		corev1.ResourceCPU:    apiresource.MustParse("20m"),
+		corev1.ResourceMemory: apiresource.MustParse("32Mi"),
+	},
+	Limits: corev1.ResourceList{
+		corev1.ResourceCPU:    apiresource.MustParse("250m"),
+		corev1.ResourceMemory: apiresource.MustParse("256Mi"),
+	},
+}
+
+// DefaultKinds are the built-in device driver kinds.
+var DefaultKinds = map[ndddvrv1.DeviceDriverKind]KindDefaults{
+	ndddvrv1.DeviceDriverKindGnmi: {
+		Image:           "yndd/ndd-gnmi:v0.1.0",
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/ddriver"},
+		Resources:       defaultResources,
+	},
+	ndddvrv1.DeviceDriverKindNetconf: {
+		Image:           "yndd/ndd-netconf:v0.1.0",
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/ddriver"},
+		Resources:       defaultResources,
+	},
+}
+
+// A KindRegistry returns the defaults of the device driver kinds.
+type KindRegistry interface {
+	// Get returns the defaults of the device driver kind
+	Get(ctx context.Context, kind ndddvrv1.DeviceDriverKind) (*KindDefaults, error)
+}
+
+// An unknownKindError is returned when a device driver kind is not registered.
+type unknownKindError struct {
+	kind ndddvrv1.DeviceDriverKind
+}
+
+func (e *unknownKindError) Error() string {
+	return errUnknownDeviceDriverKind + ": " + string(e.kind)
+}
+
+// IsUnknownKind returns true if the error indicates the device driver kind is
+// not registered.
+func IsUnknownKind(err error) bool {
+	_, ok := errors.Cause(err).(*unknownKindError)
+	return ok
+}
+
+// ConfigMapKindRegistry returns the built-in device driver kinds extended by
+// the device driver kinds of the KindsConfigMap.
+type ConfigMapKindRegistry struct {
+	client    client.Reader
+	namespace string
+	defaults  map[ndddvrv1.DeviceDriverKind]KindDefaults
+}
+
+// NewConfigMapKindRegistry creates a new ConfigMapKindRegistry reading the
+// KindsConfigMap from the namespace.
+func NewConfigMapKindRegistry(client client.Reader, namespace string) *ConfigMapKindRegistry {
+	return &ConfigMapKindRegistry{
+		client:    client,
+		namespace: namespace,
+		defaults:  DefaultKinds,
+	}
+}
+
+// Get returns the defaults of the device driver kind from the KindsConfigMap,
+// or the built-in defaults when the configmap does not hold the kind.
+func (r *ConfigMapKindRegistry) Get(ctx context.Context, kind ndddvrv1.DeviceDriverKind) (*KindDefaults, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: r.namespace, Name: KindsConfigMap}, cm); resource.IgnoreNotFound(err) != nil {
+		return nil, errors.Wrap(err, errGetKindsConfigMap)
+	}
+	if d, ok := cm.Data[string(kind)]; ok {
+		kd := &KindDefaults{}
+		if err := yaml.Unmarshal([]byte(d), kd); err != nil {
+			return nil, errors.Wrap(err, errParseKindDefaults)
+		}
+		return kd, nil
+	}
+	if kd, ok := r.defaults[kind]; ok {
+		return &kd, nil
+	}
+	return nil, &unknownKindError{kind: kind}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// the kubernetes API server endpoints
+	apiServerNamespace = "default"
+	apiServerName      = "kubernetes"
+
+	dnsPort = 53
+
+	// Errors
+	errGetAPIServer = "cannot get kubernetes API server endpoints"
+)
+
+// NetworkPolicyEnabled returns true if the device driver pods are isolated
+// with a network policy, the device driver overrides the setting of the core.
+func NetworkPolicyEnabled(dd *ndddvrv1.DeviceDriver, enabled bool) bool {
+	if dd != nil && dd.Spec.NetworkPolicy != nil {
+		return *dd.Spec.NetworkPolicy
+	}
+	return enabled
+}
+
+// apiServerEgress returns the egress rule to the kubernetes API server, the
+// network policies apply to the endpoints of the API server service.
+func apiServerEgress(ctx context.Context, c client.Reader) (networkingv1.NetworkPolicyEgressRule, error) {
+	ep := &corev1.Endpoints{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: apiServerNamespace, Name: apiServerName}, ep); err != nil {
+		return networkingv1.NetworkPolicyEgressRule{}, errors.Wrap(err, errGetAPIServer)
+	}
+	r := networkingv1.NetworkPolicyEgressRule{}
+	for _, s := range ep.Subsets {
+		for _, a := range s.Addresses {
+			if p := ipPeer(a.IP); p != nil {
+				r.To = append(r.To, *p)
+			}
+		}
+		for _, p := range s.Ports {
+			r.Ports = append(r.Ports, port(p.Protocol, int(p.Port)))
+		}
+	}
+	return r, nil
+}
+
+// ipPeer returns the peer of the ip address, nil is returned when the host is
+// not an ip address.
+func ipPeer(host string) *networkingv1.NetworkPolicyPeer {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+	cidr := ip.String() + "/32"
+	if ip.To4() == nil {
+		cidr = ip.String() + "/128"
+	}
+	return &networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}}
+}
+
+func port(protocol corev1.Protocol, p int) networkingv1.NetworkPolicyPort {
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	pp := intstr.FromInt(p)
+	return networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &pp}
+}
+
+// hostEgress returns the egress rule to the host and port, a host that is not
+// an ip address cannot be selected by a network policy so only the port is
+// restricted.
+func hostEgress(host, p string) (networkingv1.NetworkPolicyEgressRule, bool) {
+	n, err := strconv.Atoi(p)
+	if err != nil {
+		return networkingv1.NetworkPolicyEgressRule{}, false
+	}
+	r := networkingv1.NetworkPolicyEgressRule{
+		Ports: []networkingv1.NetworkPolicyPort{port(corev1.ProtocolTCP, n)},
+	}
+	if peer := ipPeer(host); peer != nil {
+		r.To = []networkingv1.NetworkPolicyPeer{*peer}
+	}
+	return r, true
+}
+
+// targetEgress returns the egress rules to the target and the proxy of the
+// network node.
+func targetEgress(nn ndddvrv1.Nn) []networkingv1.NetworkPolicyEgressRule {
+	var rules []networkingv1.NetworkPolicyEgressRule
+	if host, p, err := net.SplitHostPort(nn.GetTargetAddress()); err == nil {
+		if r, ok := hostEgress(host, p); ok {
+			rules = append(rules, r)
+		}
+	}
+	if nn.GetTargetProxy() != "" {
+		if u, err := url.Parse(nn.GetTargetProxy()); err == nil {
+			if r, ok := hostEgress(u.Hostname(), u.Port()); ok {
+				rules = append(rules, r)
+			}
+		}
+	}
+	return rules
+}
+
+// networkPolicySpec returns the spec of the network policy of the device
+// driver pods of the application. Ingress to the grpc server is allowed from
+// the providers and the core, egress is allowed to the targets and proxies of
+// the network nodes, DNS and the kubernetes API server.
+func networkPolicySpec(app string, grpcServerPort int, nns []ndddvrv1.Nn, apiServer networkingv1.NetworkPolicyEgressRule) networkingv1.NetworkPolicySpec {
+	egress := []networkingv1.NetworkPolicyEgressRule{
+		{
+			Ports: []networkingv1.NetworkPolicyPort{
+				port(corev1.ProtocolUDP, dnsPort),
+				port(corev1.ProtocolTCP, dnsPort),
+			},
+		},
+		apiServer,
+	}
+	for _, nn := range nns {
+		egress = append(egress, targetEgress(nn)...)
+	}
+	return networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: map[string]string{ndddvrv1.LabelApplication: app},
+		},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{
+			{
+				Ports: []networkingv1.NetworkPolicyPort{port(corev1.ProtocolTCP, grpcServerPort)},
+				From: []networkingv1.NetworkPolicyPeer{
+					{
+						PodSelector: &metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{
+								{Key: ndddvrv1.LabelProviderRevision, Operator: metav1.LabelSelectorOpExists},
+							},
+						},
+					},
+					{
+						PodSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{ndddvrv1.LabelControlPlane: ndddvrv1.ControlPlaneCore},
+						},
+					},
+				},
+			},
+		},
+		Egress: egress,
+	}
+}
+
+// policyHash returns the hash of the network policy, an empty network policy
+// indicates the device driver pods are not isolated.
+func policyHash(np *networkingv1.NetworkPolicy) string {
+	if np == nil {
+		return ""
+	}
+	b, _ := json.Marshal(np.Spec) // nolint:errcheck
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+func buildNetworkPolicy(nn ndddvrv1.Nn, apiServer networkingv1.NetworkPolicyEgressRule, namespace string) *networkingv1.NetworkPolicy {
+	app := strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-")
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: nnObjectMeta(nn, namespace),
+		Spec:       networkPolicySpec(app, nn.GetGrpcServerPort(), []ndddvrv1.Nn{nn}, apiServer),
+	}
+}
+
+// emptyNetworkPolicy returns the network policy of the network node without
+// spec, to delete it.
+func emptyNetworkPolicy(nn ndddvrv1.Nn, namespace string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{ObjectMeta: nnObjectMeta(nn, namespace)}
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-runtime/pkg/meta"
+	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// items
+	kindClusterRole = "ClusterRole"
+	kindRole        = "Role"
+
+	// resources
+	resourceNetworkNodes       = "networknodes"
+	resourceNetworkNodesStatus = "networknodes/status"
+	resourceConfigMaps         = "configmaps"
+	resourceSecrets            = "secrets"
+	resourceEvents             = "events"
+)
+
+var (
+	verbsRead   = []string{"get", "list", "watch"}
+	verbsUpdate = []string{"get", "list", "watch", "update", "patch"}
+	verbsStatus = []string{"get", "update", "patch"}
+	verbsEvents = []string{"create", "update", "patch"}
+)
+
+// clusterRules returns the cluster wide rules of a device driver serving the
+// network nodes, i.e. the access to the network nodes and the additional
+// permissions of the device driver.
+func clusterRules(nodes []string, permissions []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{ndddvrv1.Group},
+			Resources:     []string{resourceNetworkNodes},
+			ResourceNames: nodes,
+			Verbs:         verbsUpdate,
+		},
+		{
+			APIGroups:     []string{ndddvrv1.Group},
+			Resources:     []string{resourceNetworkNodesStatus},
+			ResourceNames: nodes,
+			Verbs:         verbsStatus,
+		},
+	}
+	return append(rules, permissions...)
+}
+
+// eventRules returns the rules to record events.
+func eventRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{resourceEvents},
+			Verbs:     verbsEvents,
+		},
+	}
+}
+
+// namespacedRules returns the rules of a device driver in the namespace of the
+// device driver, i.e. the read access to its configmap and secrets and the
+// recording of the events of its objects. A device driver has no access to the
+// other objects in the namespace of the core.
+func namespacedRules(configMap string, secrets []string) []rbacv1.PolicyRule {
+	return append([]rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{resourceConfigMaps},
+			ResourceNames: []string{configMap},
+			Verbs:         verbsRead,
+		},
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{resourceSecrets},
+			ResourceNames: secrets,
+			Verbs:         verbsRead,
+		},
+	}, eventRules()...)
+}
+
+// secretNames returns the names of the secrets of the network node.
+func secretNames(nn ndddvrv1.Nn) []string {
+	secrets := []string{strings.Join([]string{ndddvrv1.PrefixCredentialsSecret, nn.GetName()}, "-")}
+	if nn.GetTargetTLSCredentialsName() != "" {
+		secrets = append(secrets, strings.Join([]string{ndddvrv1.PrefixTLSSecret, nn.GetName()}, "-"))
+	}
+	return secrets
+}
+
+// rbacHash returns the hash of the rules of the device driver, such that the
+// device driver is rolled out when its permissions change.
+func rbacHash(cr *rbacv1.ClusterRole, r *rbacv1.Role) string {
+	h := sha256.New()
+	for _, rules := range [][]rbacv1.PolicyRule{cr.Rules, r.Rules} {
+		b, _ := json.Marshal(rules) // nolint:errcheck
+		h.Write(b)                  // nolint:errcheck
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+func nnObjectMeta(nn ndddvrv1.Nn, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-"),
+		Namespace: namespace,
+		Labels: map[string]string{
+			ndddvrv1.LabelNetworkDeviceDriver: strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-"),
+		},
+		OwnerReferences: []metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(nn, ndddvrv1.NetworkNodeGroupVersionKind))},
+	}
+}
+
+func buildClusterRole(nn ndddvrv1.Nn, permissions []rbacv1.PolicyRule) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: nnObjectMeta(nn, ""),
+		Rules:      clusterRules([]string{nn.GetName()}, permissions),
+	}
+}
+
+func buildClusterRoleBinding(nn ndddvrv1.Nn, namespace string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: nnObjectMeta(nn, ""),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     kindClusterRole,
+			Name:     strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-"),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: namespace,
+				Name:      strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-"),
+			},
+		},
+	}
+}
+
+func buildRole(nn ndddvrv1.Nn, namespace string) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: nnObjectMeta(nn, namespace),
+		Rules:      namespacedRules(strings.Join([]string{ndddvrv1.PrefixConfigmap, nn.GetName()}, "-"), secretNames(nn)),
+	}
+}
+
+func buildRoleBinding(nn ndddvrv1.Nn, namespace string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: nnObjectMeta(nn, namespace),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     kindRole,
+			Name:     strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-"),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: namespace,
+				Name:      strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-"),
+			},
+		},
+	}
+}
+
+// buildEventsRole builds the role of the device driver in the default
+// namespace, where the events of the cluster scoped network nodes are
+// recorded. The role has the name of the service account of the device
+// driver. It returns nil when the device driver runs in the default namespace,
+// since the role of the device driver in its namespace allows to record events.
+func buildEventsRole(om metav1.ObjectMeta, namespace string) *rbacv1.Role {
+	if namespace == ndddvrv1.DefaultNamespace {
+		return nil
+	}
+	om.Namespace = ndddvrv1.DefaultNamespace
+	return &rbacv1.Role{
+		ObjectMeta: om,
+		Rules:      eventRules(),
+	}
+}
+
+// buildEventsRoleBinding builds the binding of the service account of the
+// device driver to its role in the default namespace. It returns nil when the
+// device driver runs in the default namespace.
+func buildEventsRoleBinding(om metav1.ObjectMeta, namespace string) *rbacv1.RoleBinding {
+	if namespace == ndddvrv1.DefaultNamespace {
+		return nil
+	}
+	sa := om.Name
+	om.Namespace = ndddvrv1.DefaultNamespace
+	return &rbacv1.RoleBinding{
+		ObjectMeta: om,
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     kindRole,
+			Name:     om.Name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: namespace,
+				Name:      sa,
+			},
+		},
+	}
+}
+
+// deleteEventsRole deletes the role of the device driver in the default
+// namespace and its binding.
+func deleteEventsRole(ctx context.Context, c client.Writer, om metav1.ObjectMeta, namespace string) error {
+	r := buildEventsRole(om, namespace)
+	if r == nil {
+		return nil
+	}
+	if err := c.Delete(ctx, buildEventsRoleBinding(om, namespace)); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errDeleteRoleBinding)
+	}
+	if err := c.Delete(ctx, r); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errDeleteRole)
+	}
+	return nil
+}
+
+// applyClusterRoleBinding applies the cluster role binding, the role of a
+// binding cannot be changed so a binding to another role is replaced. The
+// device drivers used to be bound to the cluster role of the core.
+func applyClusterRoleBinding(ctx context.Context, c resource.ClientApplicator, b *rbacv1.ClusterRoleBinding) error {
+	current := &rbacv1.ClusterRoleBinding{}
+	err := c.Get(ctx, types.NamespacedName{Name: b.GetName()}, current)
+	if resource.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err == nil && current.RoleRef != b.RoleRef {
+		if err := c.Delete(ctx, current); resource.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return c.Apply(ctx, b)
+}
@@ -19,18 +19,25 @@ package nn
 import (
 	"context"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
 	"github.com/netw-device-driver/ndd-runtime/pkg/event"
 	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
 	"github.com/netw-device-driver/ndd-runtime/pkg/meta"
 	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
 	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -43,10 +50,20 @@ const (
 	defaultGrpcPort = 9999
 
 	// Timers
-	reconcileTimeout = 1 * time.Minute
-	shortWait        = 30 * time.Second
-	veryShortWait    = 5 * time.Second
-	longWait         = 1 * time.Minute
+	reconcileTimeout  = 1 * time.Minute
+	shortWait         = 30 * time.Second
+	veryShortWait     = 5 * time.Second
+	longWait          = 1 * time.Minute
+	discoveryInterval = 5 * time.Minute
+	tlsExpiryWarning  = 30 * 24 * time.Hour
+
+	// the exponential backoff of the network nodes that fail the reachability
+	// check
+	reachabilityBaseBackoff = 1 * time.Second
+	reachabilityMaxBackoff  = 5 * time.Minute
+
+	// the maximum length of the diff in the rollout events
+	maxRolloutDiff = 1024
 
 	// Errors
 	errGetNetworkNode = "cannot get network node resource"
@@ -54,14 +71,25 @@ const (
 
 	errAddFinalizer    = "cannot add network node finalizer"
 	errRemoveFinalizer = "cannot remove network node finalizer"
+	errLabel           = "cannot label network node"
+	errGetClass        = "cannot get network node class"
 
-	errCredentials = "invalid credentials"
+	errCredentials           = "invalid credentials"
+	errTLSCredentials        = "invalid tls credentials"
+	errProxy                 = "invalid proxy"
+	errUnreachable           = "network node is unreachable"
+	errTLSCertificateExpired = "tls certificate is expired"
+	errSelectDeviceDriver    = "cannot select device driver"
 
 	errDeleteObjects = "cannot delete configmap, servide or deployment"
 	errCreateObjects = "cannot create configmap, servide or deployment"
+	errHealth        = "cannot determine device driver health"
+	errSuspend       = "cannot suspend device driver"
 
 	// Event reasons
-	reasonSync event.Reason = "SyncNetworkNode"
+	reasonSync      event.Reason = "SyncNetworkNode"
+	reasonDiscovery event.Reason = "DiscoverNetworkNode"
+	reasonRollout   event.Reason = "RolloutDeviceDriver"
 )
 
 // ReconcilerOption is used to configure the Reconciler.
@@ -103,20 +131,46 @@ func WithValidator(v Validator) ReconcilerOption {
 	}
 }
 
+// WithDiscoverer specifies how the Reconciler should discover the network
+// device.
+func WithDiscoverer(d Discoverer) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.discoverer = d
+	}
+}
+
+// WithNetworkPolicy specifies whether the device driver pods are isolated with
+// a network policy when the device driver does not specify it.
+func WithNetworkPolicy(enabled bool) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.networkPolicy = enabled
+	}
+}
+
 // Reconciler reconciles packages.
 type Reconciler struct {
-	client      client.Client
-	nnFinalizer resource.Finalizer
-	hooks       Hooks
-	validator   Validator
-	log         logging.Logger
-	record      event.Recorder
+	client        client.Client
+	nnFinalizer   resource.Finalizer
+	hooks         Hooks
+	validator     Validator
+	discoverer    Discoverer
+	log           logging.Logger
+	record        event.Recorder
+	networkPolicy bool
+
+	// reachabilityBackoff schedules the network nodes that fail the
+	// reachability check, independent of the rate limiter of the controller
+	reachabilityBackoff workqueue.RateLimiter
+
+	// handshakes holds the reachability hash of the last successful handshake
+	// per network node
+	handshakes sync.Map
 
 	newNetworkNode func() ndddvrv1.Nn
 }
 
 // Setup adds a controller that reconciles the Lock.
-func Setup(mgr ctrl.Manager, l logging.Logger, namespace string) error {
+func Setup(mgr ctrl.Manager, l logging.Logger, namespace, allowClusterRole string, networkPolicy bool) error {
 	name := "dvr/" + strings.ToLower(ndddvrv1.NetworkNodeKind)
 	nn := func() ndddvrv1.Nn { return &ndddvrv1.NetworkNode{} }
 
@@ -131,17 +185,54 @@ func Setup(mgr ctrl.Manager, l logging.Logger, namespace string) error {
 		WithValidator(NewNnValidator(resource.ClientApplicator{
 			Client:     mgr.GetClient(),
 			Applicator: resource.NewAPIPatchingApplicator(mgr.GetClient()),
-		}, l)),
+		}, NewConfigMapKindRegistry(mgr.GetClient(), namespace), NewPermissionRequestsValidator(mgr.GetClient(), allowClusterRole), l)),
+		WithDiscoverer(NewGrpcDiscoverer(l, namespace, WithCertificateAuthority(mgr.GetClient()))),
+		WithNetworkPolicy(networkPolicy),
 	)
 
 	h := &EnqueueRequestForAllDeviceDriversWithRequests{
 		client: mgr.GetClient()}
 
+	s := &EnqueueRequestForReferencedSecrets{
+		client: mgr.GetClient()}
+
+	p := &EnqueueRequestForDeviceDriverPods{
+		namespace: namespace}
+
+	m := &EnqueueRequestForShardMembers{
+		client:    mgr.GetClient(),
+		namespace: namespace}
+
+	k := &EnqueueRequestForDeviceDriverKinds{
+		client:    mgr.GetClient(),
+		namespace: namespace}
+
+	c := &EnqueueRequestForNetworkNodeClassMembers{
+		client: mgr.GetClient()}
+
+	// secrets have no generation and the health of the device driver is
+	// reported in the status of the deployments and pods, so the generation
+	// change predicate only applies to the network nodes and device drivers;
+	// label and annotation changes of device drivers can change the device
+	// driver selection and the rollout admits network nodes by annotation
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
-		For(&ndddvrv1.NetworkNode{}).
-		Watches(&source.Kind{Type: &ndddvrv1.DeviceDriver{}}, h).
-		WithEventFilter(resource.IgnoreUpdateWithoutGenerationChangePredicate()).
+		For(&ndddvrv1.NetworkNode{}, builder.WithPredicates(predicate.Or(
+			resource.IgnoreUpdateWithoutGenerationChangePredicate(),
+			predicate.AnnotationChangedPredicate{},
+		))).
+		Owns(&appsv1.Deployment{}).
+		Watches(&source.Kind{Type: &corev1.Pod{}}, p).
+		Watches(&source.Kind{Type: &appsv1.Deployment{}}, m).
+		Watches(&source.Kind{Type: &corev1.Pod{}}, m).
+		Watches(&source.Kind{Type: &ndddvrv1.DeviceDriver{}}, h, builder.WithPredicates(predicate.Or(
+			resource.IgnoreUpdateWithoutGenerationChangePredicate(),
+			predicate.LabelChangedPredicate{},
+			predicate.AnnotationChangedPredicate{},
+		))).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, s).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, k).
+		Watches(&source.Kind{Type: &ndddvrv1.NetworkNodeClass{}}, c).
 		Complete(r)
 }
 
@@ -150,8 +241,11 @@ func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
 	r := &Reconciler{
 		client:      mgr.GetClient(),
 		nnFinalizer: resource.NewAPIFinalizer(mgr.GetClient(), finalizer),
+		discoverer:  NewNopDiscoverer(),
 		log:         logging.NewNopLogger(),
 		record:      event.NewNopRecorder(),
+
+		reachabilityBackoff: workqueue.NewItemExponentialFailureRateLimiter(reachabilityBaseBackoff, reachabilityMaxBackoff),
 	}
 
 	for _, f := range opts {
@@ -161,16 +255,52 @@ func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
 	return r
 }
 
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;delete
+// isDeployed returns true if the device driver of the network node is deployed.
+func isDeployed(nn ndddvrv1.Nn) bool {
+	return nn.GetControllerReference().Name != ""
+}
+
+// usedDeviceDriverSpec returns the spec of the device driver used for the
+// network node, which is the effective device driver container for the
+// built-in device driver.
+func usedDeviceDriverSpec(dd *ndddvrv1.DeviceDriver, pt *corev1.PodTemplateSpec) *ndddvrv1.DeviceDriverSpec {
+	if dd != nil {
+		return dd.Spec.DeepCopy()
+	}
+	if len(pt.Spec.Containers) == 0 {
+		return &ndddvrv1.DeviceDriverSpec{}
+	}
+	return &ndddvrv1.DeviceDriverSpec{Container: pt.Spec.Containers[0].DeepCopy()}
+}
+
+// rolloutDiff returns the diff between the used specs and the rolled out specs
+// of the network node, truncated to fit in an event.
+func rolloutDiff(oldNn, newNn *ndddvrv1.NetworkNodeSpec, oldDd, newDd *ndddvrv1.DeviceDriverSpec) string {
+	diff := cmp.Diff(oldNn, newNn) + cmp.Diff(oldDd, newDd)
+	if len(diff) > maxRolloutDiff {
+		return diff[:maxRolloutDiff] + "..."
+	}
+	return diff
+}
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=pods,verbs=list;watch;get;patch;create;update;delete
-// +kubebuilder:rbac:groups="",resources=secrets,verbs=list;watch;get
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=list;watch;get;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=list;watch;get;patch;create;update;delete
 // +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=devicedrivers,verbs=get;list;watch
 // +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=networknodes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=networknodes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=networknodes/finalizers,verbs=update
+// +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=networknodeclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch;create;update;patch;delete;escalate;bind
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;create;update;patch;delete;escalate;bind
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch
 
 // Reconcile network node.
 func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) { // nolint:gocyclo
@@ -190,6 +320,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		// the k8s garbage collector will delete all the objects that has the ownerreference set
 		// as such we dont have to delete the child objects: configmap, service, deployment, serviceaccount, clusterrolebinding
 
+		r.reachabilityBackoff.Forget(req)
+		r.handshakes.Delete(req.Name)
+
 		// Delete finalizer after the object is deleted
 		if err := r.nnFinalizer.RemoveFinalizer(ctx, nn); err != nil {
 			log.Debug(errRemoveFinalizer, "error", err)
@@ -206,15 +339,63 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{RequeueAfter: shortWait}, nil
 	}
 
+	// the network node class provides the defaults of the fields the network
+	// node does not specify, the network node keeps its device driver until
+	// its class exists
+	class, err := networkNodeClass(ctx, r.client, nn)
+	if err != nil {
+		log.Debug(errGetClass, "error", err)
+		r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errGetClass)))
+		return reconcile.Result{RequeueAfter: shortWait}, nil
+	}
+	effective := ndddvrv1.EffectiveNetworkNodeSpec(&nn.Spec, class)
+
+	// the network nodes are labeled with their device driver kind, such that
+	// the providers can list the endpoints of the device driver kinds they
+	// handle by label
+	if kind := string(*effective.DeviceDriverKind); nn.GetLabels()[ndddvrv1.LabelDeviceDriverKind] != kind {
+		patch := client.MergeFrom(nn.DeepCopy())
+		meta.AddLabels(nn, map[string]string{ndddvrv1.LabelDeviceDriverKind: kind})
+		if err := r.client.Patch(ctx, nn, patch); err != nil {
+			log.Debug(errLabel, "error", err)
+			r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errLabel)))
+			return reconcile.Result{RequeueAfter: shortWait}, nil
+		}
+	}
+
+	// the rest of the reconciliation uses the effective spec, which is
+	// recorded in the status
+	nn.SetEffectiveNetworkNodeSpec(effective)
+
+	// a network node in maintenance keeps its device driver configuration and
+	// status, only the device driver is scaled to zero
+	if nn.GetMaintenance() {
+		if err := r.hooks.Suspend(ctx, nn); err != nil {
+			log.Debug(errSuspend, "error", err)
+			r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errSuspend)))
+			return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+		}
+		if nn.GetCondition(ndddvrv1.ConditionKindMaintenance).Status != corev1.ConditionTrue {
+			r.record.Event(nn, event.Normal(reasonSync, "Network node is in maintenance"))
+		}
+		setEndpointReady(nn, false)
+		nn.SetConditions(ndddvrv1.InMaintenance(), ndddvrv1.DeviceDriverSuspended(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+		return reconcile.Result{Requeue: false}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+	}
+	if nn.GetCondition(ndddvrv1.ConditionKindMaintenance).Status == corev1.ConditionTrue {
+		r.record.Event(nn, event.Normal(reasonSync, "Network node left maintenance"))
+	}
+	nn.SetConditions(ndddvrv1.NotInMaintenance())
+
 	// Retrieve the Login details from the network node spec and validate
 	// the network node details and build the credentials for communicating
 	// to the network node.
-	creds, err := r.validator.ValidateCredentials(ctx, nn.Namespace, *nn.Spec.Target.CredentialsName, *nn.Spec.Target.Address)
+	creds, err := r.validator.ValidateCredentials(ctx, nn.Namespace, nn.GetTargetCredentialsName(), nn.GetTargetAddress())
 	log.Debug("Network node creds", "creds", creds, "err", err)
 	if err != nil || creds == nil {
 		// remove delete the configmap, service, deployment when the service was healthy
-		if nn.GetCondition(ndddvrv1.ConditionKindDeviceDriverHealthy).Status == corev1.ConditionTrue {
-			if err := r.hooks.Destroy(ctx, nn, &corev1.Container{}); err != nil {
+		if isDeployed(nn) {
+			if err := r.hooks.Destroy(ctx, nn, &corev1.PodTemplateSpec{}, nil, nil); err != nil {
 				log.Debug(errDeleteObjects, "error", err)
 				r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errDeleteObjects)))
 				nn.SetConditions(ndddvrv1.Unhealthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
@@ -227,30 +408,204 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
 	}
 
-	// validate device driver information
+	// validate the proxy of the network node, the proxy credentials are passed
+	// to the device driver together with the credentials
+	creds.Proxy, err = r.validator.ValidateProxy(ctx, nn.Namespace, nn.GetTargetProxy(), nn.GetTargetProxyCredentialsName())
+	if err != nil {
+		if isDeployed(nn) {
+			if err := r.hooks.Destroy(ctx, nn, &corev1.PodTemplateSpec{}, nil, nil); err != nil {
+				log.Debug(errDeleteObjects, "error", err)
+				r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errDeleteObjects)))
+				nn.SetConditions(ndddvrv1.Unhealthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+				return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+			}
+		}
+		log.Debug(errProxy, "error", err)
+		r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errProxy)))
+		nn.SetConditions(ndddvrv1.Unhealthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+	}
+
+	// validate the tls credentials when the network node references them and
+	// report the expiry of the certificate
+	var tls *TLSCredentials
+	if nn.GetTargetTLSCredentialsName() != "" {
+		tls, err = r.validator.ValidateTLSCredentials(ctx, nn.Namespace, nn.GetTargetTLSCredentialsName())
+		if err != nil {
+			if isDeployed(nn) {
+				if err := r.hooks.Destroy(ctx, nn, &corev1.PodTemplateSpec{}, nil, nil); err != nil {
+					log.Debug(errDeleteObjects, "error", err)
+					r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errDeleteObjects)))
+					nn.SetConditions(ndddvrv1.Unhealthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+					return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+				}
+			}
+			log.Debug(errTLSCredentials, "error", err)
+			r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errTLSCredentials)))
+			nn.SetConditions(ndddvrv1.TLSCredentialsInvalid().WithMessage(err.Error()), ndddvrv1.Unhealthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+			return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+		}
+		expiry := "certificate expires at " + tls.NotAfter.UTC().Format(time.RFC3339)
+		switch {
+		case time.Now().After(tls.NotAfter):
+			log.Debug(errTLSCertificateExpired, "notAfter", tls.NotAfter)
+			r.record.Event(nn, event.Warning(reasonSync, errors.New(errTLSCertificateExpired)))
+			nn.SetConditions(ndddvrv1.TLSCredentialsExpired().WithMessage(expiry), ndddvrv1.Unhealthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+			return reconcile.Result{RequeueAfter: longWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+		case time.Until(tls.NotAfter) < tlsExpiryWarning:
+			nn.SetConditions(ndddvrv1.TLSCredentialsExpiring().WithMessage(expiry))
+		default:
+			nn.SetConditions(ndddvrv1.TLSCredentialsValid().WithMessage(expiry))
+		}
+	} else {
+		nn.SetConditions(ndddvrv1.NoTLSCredentials())
+	}
+
+	// check the network node is reachable before the device driver is
+	// deployed, unreachable network nodes are retried with exponential backoff.
+	// The protocol handshake is only performed before the device driver is
+	// deployed or when the target, the credentials or the proxy change,
+	// otherwise only the tcp connection is checked.
+	hash := reachabilityHash(nn, creds, tls)
+	handshake := !isDeployed(nn)
+	if h, ok := r.handshakes.Load(req.Name); !ok || h.(string) != hash {
+		handshake = true
+	}
+	reachability, err := r.validator.ValidateReachability(ctx, nn, creds, tls, handshake)
+	if err != nil {
+		log.Debug(errUnreachable, "error", err)
+		if nn.GetCondition(ndddvrv1.ConditionKindReachable).Status != corev1.ConditionFalse {
+			r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errUnreachable)))
+		}
+		r.handshakes.Delete(req.Name)
+		nn.SetConditions(ndddvrv1.Unreachable().WithMessage(err.Error()), ndddvrv1.NotDiscovered())
+		return reconcile.Result{RequeueAfter: r.reachabilityBackoff.When(req)}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+	}
+	r.reachabilityBackoff.Forget(req)
+	if handshake {
+		r.handshakes.Store(req.Name, hash)
+	}
+	nn.SetConditions(ndddvrv1.Reachable().WithMessage(reachability.String()))
+
+	// select the device driver of the network node and validate the device
+	// driver information
 	// NOTE: the parameters are required in the api and will get defaults if not specified, so we dont have to add validation
 	// if they exist in the api or not
-	c, err := r.validator.ValidateDeviceDriver(ctx, nn.Namespace, nn.Name, string(*nn.Spec.DeviceDriverKind), *nn.Spec.GrpcServerPort)
-	log.Debug("Validate device driver", "containerInfo", c, "err", err)
-	if err != nil || c == nil {
-		if nn.GetCondition(ndddvrv1.ConditionKindDeviceDriverHealthy).Status == corev1.ConditionTrue {
-			if err := r.hooks.Destroy(ctx, nn, &corev1.Container{}); err != nil {
+	var pt *corev1.PodTemplateSpec
+	var permissions []rbacv1.PolicyRule
+	dd, err := r.validator.SelectDeviceDriver(ctx, nn)
+	if err != nil {
+		// a conflict between device drivers or a device driver that cannot be
+		// retrieved does not take the deployed device driver down, the network
+		// node keeps running its device driver until a device driver is selected
+		log.Debug(errSelectDeviceDriver, "error", err)
+		r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errSelectDeviceDriver)))
+		nn.SetConditions(ndddvrv1.DeviceDriverNotSelected().WithMessage(err.Error()))
+		if !isDeployed(nn) {
+			nn.SetConditions(ndddvrv1.Unhealthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+		}
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+	}
+
+	// the network node keeps its revision of the device driver until the
+	// rollout of the device driver admits the network node
+	dd = rolloutDeviceDriver(nn, dd)
+	pt, err = r.validator.ValidateDeviceDriver(ctx, nn, dd)
+	log.Debug("Validate device driver", "podTemplate", pt, "err", err)
+	if err == nil {
+		// the device driver is only granted the permissions it requests when
+		// all of them are allowed
+		permissions, err = r.validator.ValidatePermissions(ctx, dd)
+	}
+	if err != nil {
+		if isDeployed(nn) {
+			if err := r.hooks.Destroy(ctx, nn, &corev1.PodTemplateSpec{}, nil, nil); err != nil {
 				log.Debug(errDeleteObjects, "error", err)
 				r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errDeleteObjects)))
 				nn.SetConditions(ndddvrv1.Unhealthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
 				return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
 			}
 		}
+		log.Debug(errSelectDeviceDriver, "error", err)
+		r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errSelectDeviceDriver)))
+		nn.SetBoundDeviceDriver(nil)
+		if IsUnknownKind(err) {
+			nn.SetConditions(ndddvrv1.UnknownDeviceDriverKind().WithMessage(err.Error()), ndddvrv1.Unhealthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+			return reconcile.Result{RequeueAfter: longWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+		}
+		nn.SetConditions(ndddvrv1.DeviceDriverNotSelected().WithMessage(err.Error()), ndddvrv1.Unhealthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+	}
+	if dd != nil {
+		nn.SetBoundDeviceDriver(&ndddvrv1.DeviceDriverReference{Name: dd.GetName(), Namespace: dd.GetNamespace()})
+		nn.SetConditions(ndddvrv1.DeviceDriverSelected().WithMessage("using device driver " + dd.GetNamespace() + "/" + dd.GetName()))
+	} else {
+		nn.SetBoundDeviceDriver(nil)
+		nn.SetConditions(ndddvrv1.DeviceDriverSelected().WithMessage("using built-in device driver"))
 	}
 
-	// when everything is validated we want to bring the deployment in healthy status by all means
-	if err := r.hooks.Deploy(ctx, nn, c); err != nil {
+	// when everything is validated we want to bring the deployment in healthy status by all means,
+	// the device driver is only rolled out when its effective configuration changes
+	var rolledOut bool
+	if dd != nil && dd.Spec.Sharding != nil {
+		// the shards are assigned and deployed by the device driver controller,
+		// the assignment is watched through the shard annotation
+		shard := nn.GetAnnotations()[ndddvrv1.AnnotationShard]
+		if !IsShardOf(dd, shard) {
+			log.Debug("Waiting for shard assignment")
+			nn.SetConditions(ndddvrv1.DeviceDriverDeploying().WithMessage("waiting for the shard assignment"), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+			return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+		}
+		rolledOut, err = r.hooks.DeployShard(ctx, nn, shard, shardGrpcServerPort(dd), creds, tls)
+	} else {
+		rolledOut, err = r.hooks.Deploy(ctx, nn, pt, permissions, NetworkPolicyEnabled(dd, r.networkPolicy), creds, tls)
+	}
+	if err != nil {
 		log.Debug(errCreateObjects, "error", err)
 		r.record.Event(nn, event.Warning(reasonSync, errors.Wrap(err, errCreateObjects)))
 		nn.SetConditions(ndddvrv1.Unhealthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
 		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
 	}
-	r.record.Event(nn, event.Normal(reasonSync, "Successfully deployed network device driver"))
-	nn.SetConditions(ndddvrv1.Healthy(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
-	return reconcile.Result{}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+	usedNnSpec, usedDdSpec := nn.GetEffectiveNetworkNodeSpec().DeepCopy(), usedDeviceDriverSpec(dd, pt)
+	if rolledOut {
+		log.Debug("Rolled out device driver", "hash", nn.GetEffectiveSpecHash())
+		r.record.Event(nn, event.Normal(reasonRollout, "Rolled out network device driver, diff: "+
+			rolloutDiff(nn.GetUsedNetworkNodeSpec(), usedNnSpec, nn.GetUsedDeviceDriverSpec(), usedDdSpec)))
+	}
+	nn.SetUsedNetworkNodeSpec(usedNnSpec)
+	nn.SetUsedDeviceDriverSpec(usedDdSpec)
+	nn.SetObservedGeneration(nn.GetGeneration())
+
+	// the device driver is only healthy once its deployment is available, the
+	// deployment and its pods are watched to observe the health changes
+	health, err := r.hooks.Health(ctx, nn)
+	if err != nil {
+		log.Debug(errHealth, "error", err)
+		nn.SetConditions(ndddvrv1.UnknownHealth(), ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+	}
+	if health.Status != corev1.ConditionTrue {
+		if nn.GetCondition(ndddvrv1.ConditionKindDeviceDriverHealthy).Reason != health.Reason && health.Reason != ndddvrv1.ConditionReasonDeploying {
+			r.record.Event(nn, event.Warning(reasonSync, errors.Errorf("%s: %s", health.Reason, health.Message)))
+		}
+		nn.SetConditions(health, ndddvrv1.NotConfigured(), ndddvrv1.NotDiscovered())
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+	}
+	setEndpointReady(nn, true)
+	nn.SetConditions(health, ndddvrv1.NotConfigured())
+
+	// discover the network device through the device driver and rerun the
+	// discovery periodically to keep the device details up to date
+	details, err := r.discoverer.Discover(ctx, nn)
+	if err != nil {
+		log.Debug(errDiscoverDevice, "error", err)
+		nn.SetConditions(ndddvrv1.NotDiscovered())
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+	}
+	if !cmp.Equal(nn.Status.DeviceDetails, details) {
+		r.record.Event(nn, event.Normal(reasonDiscovery, "Discovered network device"))
+	}
+	nn.SetDeviceDetails(details)
+	nn.SetConditions(ndddvrv1.Discovered())
+	return reconcile.Result{RequeueAfter: discoveryInterval}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
 }
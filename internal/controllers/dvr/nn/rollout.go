@@ -0,0 +1,58 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+)
+
+// DeviceDriverRevision returns the revision of the device driver spec. The
+// rollout strategy is not part of the revision, since changing it does not
+// change the device driver that is rolled out.
+func DeviceDriverRevision(spec *ndddvrv1.DeviceDriverSpec) string {
+	s := spec.DeepCopy()
+	s.Rollout = nil
+	b, _ := json.Marshal(s) // nolint:errcheck
+	return fmt.Sprintf("%x", sha256.Sum256(b))[:16]
+}
+
+// rolloutDeviceDriver returns the device driver to deploy for the network node.
+// When the device driver has a rollout strategy, a network node that runs a
+// previous revision of the device driver keeps running it until the rollout
+// admits the network node to the revision of the device driver. The shards of
+// a sharded device driver are rolled out at once.
+func rolloutDeviceDriver(nn ndddvrv1.Nn, dd *ndddvrv1.DeviceDriver) *ndddvrv1.DeviceDriver {
+	if dd == nil || dd.Spec.Rollout == nil || dd.Spec.Sharding != nil {
+		return dd
+	}
+	bound, used := nn.GetBoundDeviceDriver(), nn.GetUsedDeviceDriverSpec()
+	if bound == nil || bound.Name != dd.GetName() || bound.Namespace != dd.GetNamespace() || used == nil {
+		// the network node does not run the device driver yet
+		return dd
+	}
+	revision := DeviceDriverRevision(&dd.Spec)
+	if DeviceDriverRevision(used) == revision || nn.GetAnnotations()[ndddvrv1.AnnotationDriverRevision] == revision {
+		return dd
+	}
+	held := dd.DeepCopy()
+	held.Spec = *used.DeepCopy()
+	return held
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-runtime/pkg/meta"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// buildCredentialsSecret builds the secret holding the credentials of the
+// network node in the namespace of the device driver.
+func buildCredentialsSecret(nn ndddvrv1.Nn, namespace string, creds *Credentials) *corev1.Secret {
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      strings.Join([]string{ndddvrv1.PrefixCredentialsSecret, nn.GetName()}, "-"),
+			Namespace: namespace,
+			Labels: map[string]string{
+				ndddvrv1.LabelNetworkDeviceDriver: strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-"),
+			},
+			OwnerReferences: []metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(nn, ndddvrv1.NetworkNodeGroupVersionKind))},
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+	if creds != nil {
+		s.Data = map[string][]byte{
+			ndddvrv1.CredentialsUsernameKey: []byte(creds.Username),
+			ndddvrv1.CredentialsPasswordKey: []byte(creds.Password),
+		}
+		if creds.Proxy != nil {
+			s.Data[ndddvrv1.ProxyUsernameKey] = []byte(creds.Proxy.Username)
+			s.Data[ndddvrv1.ProxyPasswordKey] = []byte(creds.Proxy.Password)
+		}
+	}
+	return s
+}
+
+// buildTLSSecret builds the secret holding the tls credentials of the network
+// node in the namespace of the device driver, since the secret referenced by
+// the network node cannot be mounted from another namespace.
+func buildTLSSecret(nn ndddvrv1.Nn, namespace string, tls *TLSCredentials) *corev1.Secret {
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      strings.Join([]string{ndddvrv1.PrefixTLSSecret, nn.GetName()}, "-"),
+			Namespace: namespace,
+			Labels: map[string]string{
+				ndddvrv1.LabelNetworkDeviceDriver: strings.Join([]string{ndddvrv1.PrefixNetworkNode, nn.GetName()}, "-"),
+			},
+			OwnerReferences: []metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(nn, ndddvrv1.NetworkNodeGroupVersionKind))},
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+	if tls != nil {
+		s.Data = map[string][]byte{
+			ndddvrv1.TLSCAKey:   tls.CA,
+			ndddvrv1.TLSCertKey: tls.Cert,
+			ndddvrv1.TLSKeyKey:  tls.Key,
+		}
+	}
+	return s
+}
+
+// secretHash returns the hash of the content of the secrets, which is used to
+// roll the device driver pod when the credentials are rotated. Secrets that
+// are nil are skipped.
+func secretHash(secrets ...*corev1.Secret) string {
+	h := sha256.New()
+	for _, s := range secrets {
+		if s == nil {
+			continue
+		}
+		keys := make([]string, 0, len(s.Data))
+		for k := range s.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		h.Write([]byte(s.GetName())) // nolint:errcheck
+		for _, k := range keys {
+			h.Write([]byte(k)) // nolint:errcheck
+			h.Write(s.Data[k]) // nolint:errcheck
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
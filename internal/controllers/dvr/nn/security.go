@@ -0,0 +1,121 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-runtime/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	runAsUser  = int64(2000)
+	runAsGroup = int64(2000)
+
+	// the writable temporary directory of the device driver container, which
+	// has a read only root filesystem
+	tmpVolume    = "tmp"
+	tmpMountPath = "/tmp"
+)
+
+// defaultPodSecurityContext returns the secure default security context of a
+// device driver pod.
+func defaultPodSecurityContext() *corev1.PodSecurityContext {
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot: utils.BoolPtr(true),
+		RunAsUser:    utils.Int64Ptr(runAsUser),
+		RunAsGroup:   utils.Int64Ptr(runAsGroup),
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
+
+// defaultSecurityContext returns the secure default security context of a
+// device driver container.
+func defaultSecurityContext() *corev1.SecurityContext {
+	return &corev1.SecurityContext{
+		RunAsNonRoot:             utils.BoolPtr(true),
+		RunAsUser:                utils.Int64Ptr(runAsUser),
+		RunAsGroup:               utils.Int64Ptr(runAsGroup),
+		Privileged:               utils.BoolPtr(false),
+		AllowPrivilegeEscalation: utils.BoolPtr(false),
+		ReadOnlyRootFilesystem:   utils.BoolPtr(true),
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
+
+// securePodTemplate applies the security contexts to the pod template of the
+// device driver. The security contexts of the device driver override the
+// security contexts of the pod template, the pod, the init containers and the
+// containers without security context get the secure defaults. The device
+// driver container gets an empty dir at /tmp, since its root filesystem is
+// read only.
+func securePodTemplate(pt *corev1.PodTemplateSpec, dd *ndddvrv1.DeviceDriver) {
+	if dd != nil && dd.Spec.PodSecurityContext != nil {
+		pt.Spec.SecurityContext = dd.Spec.PodSecurityContext.DeepCopy()
+	}
+	if pt.Spec.SecurityContext == nil {
+		pt.Spec.SecurityContext = defaultPodSecurityContext()
+	}
+	if dd != nil && dd.Spec.SecurityContext != nil && len(pt.Spec.Containers) > 0 {
+		pt.Spec.Containers[0].SecurityContext = dd.Spec.SecurityContext.DeepCopy()
+	}
+	for i := range pt.Spec.InitContainers {
+		if pt.Spec.InitContainers[i].SecurityContext == nil {
+			pt.Spec.InitContainers[i].SecurityContext = defaultSecurityContext()
+		}
+	}
+	for i := range pt.Spec.Containers {
+		if pt.Spec.Containers[i].SecurityContext == nil {
+			pt.Spec.Containers[i].SecurityContext = defaultSecurityContext()
+		}
+	}
+	if len(pt.Spec.Containers) > 0 {
+		addTmpVolume(pt, &pt.Spec.Containers[0])
+	}
+}
+
+// addTmpVolume mounts an empty dir at /tmp in the container, unless the
+// container already mounts a volume at /tmp.
+func addTmpVolume(pt *corev1.PodTemplateSpec, c *corev1.Container) {
+	for _, m := range c.VolumeMounts {
+		if m.MountPath == tmpMountPath {
+			return
+		}
+	}
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+		Name:      tmpVolume,
+		MountPath: tmpMountPath,
+	})
+	for _, v := range pt.Spec.Volumes {
+		if v.Name == tmpVolume {
+			return
+		}
+	}
+	pt.Spec.Volumes = append(pt.Spec.Volumes, corev1.Volume{
+		Name: tmpVolume,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+}
@@ -0,0 +1,707 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/pki"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/meta"
+	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	"github.com/netw-device-driver/ndd-runtime/pkg/utils"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// the length of the hash of the device driver in the shard names, which
+	// keeps the shard names well within the 63 characters of a label value
+	shardHashLength = 10
+
+	// Errors
+	errBuildShardConfigMap = "cannot build shard config map"
+	errListShards          = "cannot list shards"
+	errDeleteShard         = "cannot delete shard"
+	errValidateShard       = "cannot validate shard device driver"
+)
+
+// shardPrefix returns the prefix of the names of the shards of the device
+// driver. The namespace and the name of the device driver are hashed, since
+// joining them is ambiguous when they contain dashes and can exceed the
+// length of a label value.
+func shardPrefix(dd *ndddvrv1.DeviceDriver) string {
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(dd.GetNamespace()+"/"+dd.GetName())))
+	return strings.Join([]string{ndddvrv1.PrefixShard, hash[:shardHashLength]}, "-") + "-"
+}
+
+// ShardName returns the name of the shard with the index of the device driver.
+func ShardName(dd *ndddvrv1.DeviceDriver, index int) string {
+	return shardPrefix(dd) + strconv.Itoa(index)
+}
+
+// shardIndex returns the index of the shard of the device driver, or false when
+// the shard does not belong to the device driver.
+func shardIndex(dd *ndddvrv1.DeviceDriver, shard string) (int, bool) {
+	prefix := shardPrefix(dd)
+	if !strings.HasPrefix(shard, prefix) {
+		return 0, false
+	}
+	i, err := strconv.Atoi(strings.TrimPrefix(shard, prefix))
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return i, true
+}
+
+// IsShardOf returns true if the shard belongs to the device driver.
+func IsShardOf(dd *ndddvrv1.DeviceDriver, shard string) bool {
+	_, ok := shardIndex(dd, shard)
+	return ok
+}
+
+// shardGrpcServerPort returns the port of the grpc server of the shards of the
+// device driver.
+func shardGrpcServerPort(dd *ndddvrv1.DeviceDriver) int {
+	if dd.Spec.Sharding == nil || dd.Spec.Sharding.GrpcServerPort == nil {
+		return defaultGrpcPort
+	}
+	return *dd.Spec.Sharding.GrpcServerPort
+}
+
+// AssignShards assigns the network nodes to the shards of the sharded device
+// driver and returns the shard of every network node. A network node keeps its
+// shard as long as the shard exists and has room for it, the other network
+// nodes are assigned to the shards with room in the order of the shards. The
+// number of shards follows the number of network nodes, when shards are added
+// or removed the network nodes are rebalanced over the shards.
+func AssignShards(dd *ndddvrv1.DeviceDriver, nns []ndddvrv1.Nn) map[string]string {
+	perShard := 1
+	if dd.Spec.Sharding != nil && dd.Spec.Sharding.TargetsPerShard > 1 {
+		perShard = dd.Spec.Sharding.TargetsPerShard
+	}
+	shards := (len(nns) + perShard - 1) / perShard
+
+	sorted := append([]ndddvrv1.Nn{}, nns...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GetName() < sorted[j].GetName() })
+
+	assigned := make(map[string]string, len(sorted))
+	members := make([]int, shards)
+	var unassigned []ndddvrv1.Nn
+	for _, nn := range sorted {
+		i, ok := shardIndex(dd, nn.GetAnnotations()[ndddvrv1.AnnotationShard])
+		if !ok || i >= shards || members[i] >= perShard {
+			unassigned = append(unassigned, nn)
+			continue
+		}
+		assigned[nn.GetName()] = ShardName(dd, i)
+		members[i]++
+	}
+	i := 0
+	for _, nn := range unassigned {
+		for members[i] >= perShard {
+			i++
+		}
+		assigned[nn.GetName()] = ShardName(dd, i)
+		members[i]++
+	}
+	return assigned
+}
+
+// shardLabels returns the labels of the objects of the shard.
+func shardLabels(dd *ndddvrv1.DeviceDriver, shard string) map[string]string {
+	return map[string]string{
+		ndddvrv1.LabelApplication:      shard,
+		ndddvrv1.LabelDeviceDriverName: dd.GetName(),
+		ndddvrv1.LabelDeviceDriverNs:   dd.GetNamespace(),
+	}
+}
+
+// shardObjectMeta returns the object meta of the cluster scoped objects of the
+// shard.
+func shardObjectMeta(dd *ndddvrv1.DeviceDriver, shard string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:   shard,
+		Labels: shardLabels(dd, shard),
+	}
+}
+
+// buildShardTargetConfig builds the target config of a network node served by
+// a shard, the secrets of the network node are mounted in a directory per
+// network node.
+func buildShardTargetConfig(nn ndddvrv1.Nn) ndddvrv1.DeviceDriverShardTargetConfig {
+	t := buildDeviceDriverConfig(nn).Target
+	t.Credentials = path.Join(ndddvrv1.CredentialsMountPath, nn.GetName())
+	if nn.GetTargetTLSCredentialsName() != "" {
+		t.TLSCA = path.Join(ndddvrv1.TLSMountPath, nn.GetName(), ndddvrv1.TLSCAKey)
+		t.TLSCert = path.Join(ndddvrv1.TLSMountPath, nn.GetName(), ndddvrv1.TLSCertKey)
+		t.TLSKey = path.Join(ndddvrv1.TLSMountPath, nn.GetName(), ndddvrv1.TLSKeyKey)
+	}
+	if nn.GetTargetProxyCredentialsName() != "" {
+		t.ProxyCredentials = path.Join(ndddvrv1.ProxyCredentialsMountPath, nn.GetName())
+	}
+	return ndddvrv1.DeviceDriverShardTargetConfig{
+		NetworkNodeName: nn.GetName(),
+		Target:          t,
+	}
+}
+
+func buildShardConfigMap(dd *ndddvrv1.DeviceDriver, shard string, nns []ndddvrv1.Nn, namespace string, serverTLS bool) (*corev1.ConfigMap, error) {
+	kind, ok := dd.GetLabels()[ndddvrv1.LabelDeviceDriverKind]
+	if !ok {
+		kind = string(ndddvrv1.DeviceDriverKindGnmi)
+	}
+	c := &ndddvrv1.DeviceDriverShardConfig{
+		Version:          ndddvrv1.DeviceDriverConfigVersion,
+		Shard:            shard,
+		DeviceDriverKind: ndddvrv1.DeviceDriverKind(kind),
+		GrpcServerPort:   shardGrpcServerPort(dd),
+		Targets:          make([]ndddvrv1.DeviceDriverShardTargetConfig, 0, len(nns)),
+	}
+	for _, nn := range nns {
+		c.Targets = append(c.Targets, buildShardTargetConfig(nn))
+	}
+	if serverTLS {
+		c.ServerTLS = serverTLSConfig()
+	}
+	cfg, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      shard,
+			Namespace: namespace,
+			Labels:    shardLabels(dd, shard),
+		},
+		Data: map[string]string{
+			ndddvrv1.ConfigmapJsonConfig: string(cfg),
+		},
+	}, nil
+}
+
+func buildShardServiceAccount(dd *ndddvrv1.DeviceDriver, shard, namespace string) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      shard,
+			Namespace: namespace,
+			Labels:    shardLabels(dd, shard),
+		},
+	}
+}
+
+// buildShardClusterRole builds the cluster role of the shard, which grants the
+// access to the network nodes served by the shard.
+func buildShardClusterRole(dd *ndddvrv1.DeviceDriver, shard string, nns []ndddvrv1.Nn, permissions []rbacv1.PolicyRule) *rbacv1.ClusterRole {
+	nodes := make([]string, 0, len(nns))
+	for _, nn := range nns {
+		nodes = append(nodes, nn.GetName())
+	}
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   shard,
+			Labels: shardLabels(dd, shard),
+		},
+		Rules: clusterRules(nodes, permissions),
+	}
+}
+
+func buildShardClusterRoleBinding(dd *ndddvrv1.DeviceDriver, shard, namespace string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   shard,
+			Labels: shardLabels(dd, shard),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     kindClusterRole,
+			Name:     shard,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: namespace,
+				Name:      shard,
+			},
+		},
+	}
+}
+
+// buildShardRole builds the role of the shard, which grants the access to the
+// configmap of the shard and the secrets of the network nodes served by the
+// shard.
+func buildShardRole(dd *ndddvrv1.DeviceDriver, shard string, nns []ndddvrv1.Nn, namespace string) *rbacv1.Role {
+	var secrets []string
+	for _, nn := range nns {
+		secrets = append(secrets, secretNames(nn)...)
+	}
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      shard,
+			Namespace: namespace,
+			Labels:    shardLabels(dd, shard),
+		},
+		Rules: namespacedRules(shard, secrets),
+	}
+}
+
+func buildShardRoleBinding(dd *ndddvrv1.DeviceDriver, shard, namespace string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      shard,
+			Namespace: namespace,
+			Labels:    shardLabels(dd, shard),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     kindRole,
+			Name:     shard,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: namespace,
+				Name:      shard,
+			},
+		},
+	}
+}
+
+// buildShardServerCertSecret builds the secret holding the serving certificate
+// of the shard, which is valid for the services of the network nodes served by
+// the shard.
+func buildShardServerCertSecret(ctx context.Context, c client.Reader, ca *pki.CA, dd *ndddvrv1.DeviceDriver, shard string, nns []ndddvrv1.Nn, namespace string) (*corev1.Secret, error) {
+	services := make([]string, 0, len(nns))
+	for _, nn := range nns {
+		services = append(services, strings.Join([]string{ndddvrv1.PrefixService, nn.GetName()}, "-"))
+	}
+	om := metav1.ObjectMeta{
+		Name:      shard,
+		Namespace: namespace,
+		Labels:    shardLabels(dd, shard),
+	}
+	return buildServerCertSecret(ctx, c, ca, om, shard, services)
+}
+
+// buildShardNetworkPolicy builds the network policy of the shard, which allows
+// egress to the targets of the network nodes served by the shard.
+func buildShardNetworkPolicy(dd *ndddvrv1.DeviceDriver, shard string, nns []ndddvrv1.Nn, apiServer networkingv1.NetworkPolicyEgressRule, namespace string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      shard,
+			Namespace: namespace,
+			Labels:    shardLabels(dd, shard),
+		},
+		Spec: networkPolicySpec(shard, shardGrpcServerPort(dd), nns, apiServer),
+	}
+}
+
+// projectedSecretVolume returns a volume projecting the keys of the secrets of
+// the network nodes in a directory per network node, the secrets are optional
+// since they are created by the network node controller.
+func projectedSecretVolume(name string, nns []ndddvrv1.Nn, secret func(nn ndddvrv1.Nn) string, keys map[string]string) *corev1.Volume {
+	sources := make([]corev1.VolumeProjection, 0, len(nns))
+	for _, nn := range nns {
+		s := secret(nn)
+		if s == "" {
+			continue
+		}
+		items := make([]corev1.KeyToPath, 0, len(keys))
+		for k, p := range keys {
+			items = append(items, corev1.KeyToPath{Key: k, Path: path.Join(nn.GetName(), p)})
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: s},
+				Items:                items,
+				Optional:             utils.BoolPtr(true),
+			},
+		})
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+	return &corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+		},
+	}
+}
+
+// buildShardDeployment builds the deployment of the shard from the pod template
+// of the device driver, the ndd owned fields of the pod template are
+// overwritten as for the deployment of a single network node.
+func buildShardDeployment(dd *ndddvrv1.DeviceDriver, shard string, pt *corev1.PodTemplateSpec, nns []ndddvrv1.Nn, namespace string, serverTLS bool, podAnnotations map[string]string) *appsv1.Deployment {
+	t := pt.DeepCopy()
+	if len(t.Spec.Containers) == 0 {
+		t.Spec.Containers = []corev1.Container{{}}
+	}
+	if t.Labels == nil {
+		t.Labels = map[string]string{}
+	}
+	for k, v := range shardLabels(dd, shard) {
+		t.Labels[k] = v
+	}
+	for k, v := range podAnnotations {
+		if t.Annotations == nil {
+			t.Annotations = map[string]string{}
+		}
+		t.Annotations[k] = v
+	}
+	t.Spec.ServiceAccountName = shard
+
+	volumes := []corev1.Volume{
+		{
+			Name: ndddvrv1.ConfigmapVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: shard},
+				},
+			},
+		},
+	}
+	mounts := []corev1.VolumeMount{
+		{
+			Name:      ndddvrv1.ConfigmapVolume,
+			MountPath: ndddvrv1.ConfigmapMountPath,
+			ReadOnly:  true,
+		},
+	}
+	if serverTLS {
+		v, vm := serverCertVolume(shard)
+		volumes = append(volumes, v)
+		mounts = append(mounts, vm)
+	}
+	projected := []struct {
+		name      string
+		mountPath string
+		secret    func(nn ndddvrv1.Nn) string
+		keys      map[string]string
+	}{
+		{
+			name:      ndddvrv1.CredentialsVolume,
+			mountPath: ndddvrv1.CredentialsMountPath,
+			secret: func(nn ndddvrv1.Nn) string {
+				return strings.Join([]string{ndddvrv1.PrefixCredentialsSecret, nn.GetName()}, "-")
+			},
+			keys: map[string]string{
+				ndddvrv1.CredentialsUsernameKey: ndddvrv1.CredentialsUsernameKey,
+				ndddvrv1.CredentialsPasswordKey: ndddvrv1.CredentialsPasswordKey,
+			},
+		},
+		{
+			name:      ndddvrv1.TLSVolume,
+			mountPath: ndddvrv1.TLSMountPath,
+			secret: func(nn ndddvrv1.Nn) string {
+				if nn.GetTargetTLSCredentialsName() == "" {
+					return ""
+				}
+				return strings.Join([]string{ndddvrv1.PrefixTLSSecret, nn.GetName()}, "-")
+			},
+			keys: map[string]string{
+				ndddvrv1.TLSCAKey:   ndddvrv1.TLSCAKey,
+				ndddvrv1.TLSCertKey: ndddvrv1.TLSCertKey,
+				ndddvrv1.TLSKeyKey:  ndddvrv1.TLSKeyKey,
+			},
+		},
+		{
+			name:      ndddvrv1.ProxyCredentialsVolume,
+			mountPath: ndddvrv1.ProxyCredentialsMountPath,
+			secret: func(nn ndddvrv1.Nn) string {
+				if nn.GetTargetProxyCredentialsName() == "" {
+					return ""
+				}
+				return strings.Join([]string{ndddvrv1.PrefixCredentialsSecret, nn.GetName()}, "-")
+			},
+			keys: map[string]string{
+				ndddvrv1.ProxyUsernameKey: ndddvrv1.CredentialsUsernameKey,
+				ndddvrv1.ProxyPasswordKey: ndddvrv1.CredentialsPasswordKey,
+			},
+		},
+	}
+	for _, p := range projected {
+		v := projectedSecretVolume(p.name, nns, p.secret, p.keys)
+		if v == nil {
+			continue
+		}
+		volumes = append(volumes, *v)
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      p.name,
+			MountPath: p.mountPath,
+			ReadOnly:  true,
+		})
+	}
+	t.Spec.Volumes = append(userVolumes(t.Spec.Volumes), volumes...)
+	t.Spec.Containers[0].Env = userEnv(t.Spec.Containers[0].Env)
+	t.Spec.Containers[0].VolumeMounts = append(userVolumeMounts(t.Spec.Containers[0].VolumeMounts), mounts...)
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      shard,
+			Namespace: namespace,
+			Labels:    shardLabels(dd, shard),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: utils.Int32Ptr(1),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					ndddvrv1.LabelApplication: shard,
+				},
+			},
+			Template: *t,
+		},
+	}
+}
+
+// buildShardService builds the service of a network node served by a shard,
+// the service keeps the name of the service of the network node such that the
+// network node can be found at the same endpoint, it selects the pods of the
+// shard of the network node.
+func buildShardService(nn ndddvrv1.Nn, shard string, port int, namespace string) *corev1.Service {
+	s := buildService(nn, namespace)
+	s.Spec.Selector = map[string]string{
+		ndddvrv1.LabelApplication: shard,
+	}
+	for i := range s.Spec.Ports {
+		s.Spec.Ports[i].TargetPort = intstr.FromInt(port)
+	}
+	return s
+}
+
+// A ShardHooks deploys the shards of a sharded device driver.
+type ShardHooks interface {
+	// Deploy deploys the shard of the device driver serving the network nodes.
+	Deploy(ctx context.Context, dd *ndddvrv1.DeviceDriver, shard string, nns []ndddvrv1.Nn) error
+
+	// Prune deletes the shards of the device driver that are not in the shards.
+	Prune(ctx context.Context, dd *ndddvrv1.DeviceDriver, shards map[string]bool) error
+}
+
+// DeviceDriverShardHooks performs operations to deploy the shards of the
+// sharded device drivers.
+type DeviceDriverShardHooks struct {
+	client        resource.ClientApplicator
+	validator     Validator
+	log           logging.Logger
+	namespace     string
+	networkPolicy bool
+}
+
+// NewDeviceDriverShardHooks creates a new DeviceDriverShardHooks.
+func NewDeviceDriverShardHooks(client resource.ClientApplicator, validator Validator, log logging.Logger, namespace string, networkPolicy bool) *DeviceDriverShardHooks {
+	return &DeviceDriverShardHooks{
+		client:        client,
+		validator:     validator,
+		log:           log,
+		namespace:     namespace,
+		networkPolicy: networkPolicy,
+	}
+}
+
+// Deploy deploys the shard of the device driver serving the network nodes. The
+// objects of the shard are applied when they are missing or differ from the
+// effective configuration of the shard, the deployment is only applied when
+// the effective configuration of the shard changed.
+func (h *DeviceDriverShardHooks) Deploy(ctx context.Context, dd *ndddvrv1.DeviceDriver, shard string, nns []ndddvrv1.Nn) error {
+	pt, err := h.validator.ValidateShardDeviceDriver(ctx, dd, shard)
+	if err != nil {
+		return errors.Wrap(err, errValidateShard)
+	}
+	ca, err := pki.LoadCA(ctx, h.client, h.namespace)
+	if err != nil {
+		return err
+	}
+	var sc *corev1.Secret
+	if ca != nil {
+		if sc, err = buildShardServerCertSecret(ctx, h.client, ca, dd, shard, nns, h.namespace); err != nil {
+			return err
+		}
+	}
+	cm, err := buildShardConfigMap(dd, shard, nns, h.namespace, sc != nil)
+	if err != nil {
+		return errors.Wrap(err, errBuildShardConfigMap)
+	}
+	permissions, err := h.validator.ValidatePermissions(ctx, dd)
+	if err != nil {
+		return errors.Wrap(err, errValidateShard)
+	}
+	sa := buildShardServiceAccount(dd, shard, h.namespace)
+	cr := buildShardClusterRole(dd, shard, nns, permissions)
+	crb := buildShardClusterRoleBinding(dd, shard, h.namespace)
+	r := buildShardRole(dd, shard, nns, h.namespace)
+	rb := buildShardRoleBinding(dd, shard, h.namespace)
+	er := buildEventsRole(shardObjectMeta(dd, shard), h.namespace)
+	erb := buildEventsRoleBinding(shardObjectMeta(dd, shard), h.namespace)
+	podAnnotations := map[string]string{
+		ndddvrv1.AnnotationConfigHash: configHash(cm),
+		ndddvrv1.AnnotationRBACHash:   rbacHash(cr, r),
+	}
+	if sc != nil {
+		podAnnotations[ndddvrv1.AnnotationSecretHash] = secretHash(sc)
+	}
+	var np *networkingv1.NetworkPolicy
+	if NetworkPolicyEnabled(dd, h.networkPolicy) {
+		apiServer, err := apiServerEgress(ctx, h.client)
+		if err != nil {
+			return err
+		}
+		np = buildShardNetworkPolicy(dd, shard, nns, apiServer, h.namespace)
+		podAnnotations[ndddvrv1.AnnotationPolicyHash] = policyHash(np)
+	}
+	d := buildShardDeployment(dd, shard, pt, nns, h.namespace, sc != nil, podAnnotations)
+	hash := specHash(d, &corev1.Service{})
+
+	if err := applyIfChanged(ctx, h.client, cm); err != nil {
+		return errors.Wrap(err, errApplyConfigMap)
+	}
+	if err := applyIfChanged(ctx, h.client, sa); err != nil {
+		return errors.Wrap(err, errApplyServiceAccount)
+	}
+	if np != nil {
+		if err := applyIfChanged(ctx, h.client, np); err != nil {
+			return errors.Wrap(err, errApplyNetworkPolicy)
+		}
+	} else {
+		np := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: h.namespace, Name: shard}}
+		if err := h.client.Delete(ctx, np); resource.IgnoreNotFound(err) != nil {
+			return errors.Wrap(err, errDeleteNetworkPolicy)
+		}
+	}
+	if sc != nil {
+		if err := applyIfChanged(ctx, h.client, sc); err != nil {
+			return errors.Wrap(err, errApplyServerCert)
+		}
+	} else {
+		sc := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: h.namespace, Name: shard}}
+		if err := h.client.Delete(ctx, sc); resource.IgnoreNotFound(err) != nil {
+			return errors.Wrap(err, errDeleteServerCert)
+		}
+	}
+	if err := applyIfChanged(ctx, h.client, r); err != nil {
+		return errors.Wrap(err, errApplyRole)
+	}
+	if err := applyIfChanged(ctx, h.client, rb); err != nil {
+		return errors.Wrap(err, errApplyRoleBinding)
+	}
+	if er != nil {
+		if err := applyIfChanged(ctx, h.client, er); err != nil {
+			return errors.Wrap(err, errApplyRole)
+		}
+		if err := applyIfChanged(ctx, h.client, erb); err != nil {
+			return errors.Wrap(err, errApplyRoleBinding)
+		}
+	}
+	if err := applyIfChanged(ctx, h.client, cr); err != nil {
+		return errors.Wrap(err, errApplyClusterRole)
+	}
+	changed, err := objectChanged(ctx, h.client, crb)
+	if err != nil {
+		return errors.Wrap(err, errAppyClusterRoleBinding)
+	}
+	if changed {
+		if err := applyClusterRoleBinding(ctx, h.client, crb); err != nil {
+			return errors.Wrap(err, errAppyClusterRoleBinding)
+		}
+	}
+
+	// the deployment is only applied when the hash of the configuration it
+	// was rolled out with changes, such that the shard is not rolled out on
+	// every reconcile
+	current := &appsv1.Deployment{}
+	err = h.client.Get(ctx, types.NamespacedName{Namespace: d.GetNamespace(), Name: d.GetName()}, current)
+	if resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errGetDeployment)
+	}
+	if err == nil && current.GetAnnotations()[ndddvrv1.AnnotationSpecHash] == hash {
+		return nil
+	}
+	meta.AddAnnotations(d, map[string]string{ndddvrv1.AnnotationSpecHash: hash})
+	h.log.Debug("Deploy shard", "shard", shard, "targets", len(nns))
+	return errors.Wrap(h.client.Apply(ctx, d), errApplyDeployment)
+}
+
+// Prune deletes the shards of the device driver that are not in the shards.
+func (h *DeviceDriverShardHooks) Prune(ctx context.Context, dd *ndddvrv1.DeviceDriver, shards map[string]bool) error {
+	l := &appsv1.DeploymentList{}
+	if err := h.client.List(ctx, l, client.InNamespace(h.namespace), client.MatchingLabels{
+		ndddvrv1.LabelDeviceDriverName: dd.GetName(),
+		ndddvrv1.LabelDeviceDriverNs:   dd.GetNamespace(),
+	}); err != nil {
+		return errors.Wrap(err, errListShards)
+	}
+	for _, d := range l.Items {
+		shard := d.GetName()
+		if shards[shard] {
+			continue
+		}
+		h.log.Debug("Prune shard", "shard", shard)
+		for _, o := range []client.Object{
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: h.namespace, Name: shard}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: h.namespace, Name: shard}},
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: h.namespace, Name: shard}},
+			&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: shard}},
+			&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: shard}},
+			&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: h.namespace, Name: shard}},
+			&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: h.namespace, Name: shard}},
+			&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: h.namespace, Name: shard}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: h.namespace, Name: shard}},
+		} {
+			if err := h.client.Delete(ctx, o); resource.IgnoreNotFound(err) != nil {
+				return errors.Wrap(err, errDeleteShard)
+			}
+		}
+		if err := deleteEventsRole(ctx, h.client, shardObjectMeta(dd, shard), h.namespace); err != nil {
+			return errors.Wrap(err, errDeleteShard)
+		}
+	}
+	return nil
+}
+
+// NopShardHooks performs no operations.
+type NopShardHooks struct{}
+
+// NewNopShardHooks creates a shard hook that does nothing.
+func NewNopShardHooks() *NopShardHooks {
+	return &NopShardHooks{}
+}
+
+// Deploy does nothing and returns nil.
+func (h *NopShardHooks) Deploy(ctx context.Context, dd *ndddvrv1.DeviceDriver, shard string, nns []ndddvrv1.Nn) error {
+	return nil
+}
+
+// Prune does nothing and returns nil.
+func (h *NopShardHooks) Prune(ctx context.Context, dd *ndddvrv1.DeviceDriver, shards map[string]bool) error {
+	return nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newFakeClientApplicator returns a client applicator of a fake client holding
+// the objects.
+func newFakeClientApplicator(t *testing.T, objs ...client.Object) resource.ClientApplicator {
+	t.Helper()
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme(...): %v", err)
+	}
+	if err := ndddvrv1.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme(...): %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+	return resource.ClientApplicator{Client: c, Applicator: resource.NewAPIPatchingApplicator(c)}
+}
+
+// ignoreResourceVersion ignores the resource version of the objects, which
+// changes when an object is recreated.
+var ignoreResourceVersion = cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ResourceVersion")
+
+func TestDeviceDriverShardHooksDeploy(t *testing.T) {
+	dd := &ndddvrv1.DeviceDriver{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ndddvrv1.DefaultNamespace, Name: "gnmi"},
+		Spec:       ndddvrv1.DeviceDriverSpec{Sharding: &ndddvrv1.ShardingSpec{}},
+	}
+	shard := ShardName(dd, 0)
+	key := types.NamespacedName{Namespace: ndddvrv1.Namespace, Name: shard}
+
+	cases := map[string]struct {
+		reason string
+		object client.Object
+		mutate func(ctx context.Context, c client.Client) error
+	}{
+		"ConfigMapDeleted": {
+			reason: "A shard config map that is deleted out of band should be restored.",
+			object: &corev1.ConfigMap{},
+			mutate: func(ctx context.Context, c client.Client) error {
+				return c.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}})
+			},
+		},
+		"ConfigMapChanged": {
+			reason: "A shard config map that is changed out of band should be repaired.",
+			object: &corev1.ConfigMap{},
+			mutate: func(ctx context.Context, c client.Client) error {
+				cm := &corev1.ConfigMap{}
+				if err := c.Get(ctx, key, cm); err != nil {
+					return err
+				}
+				cm.Data[ndddvrv1.ConfigmapJsonConfig] = "{}"
+				return c.Update(ctx, cm)
+			},
+		},
+		"ServiceAccountDeleted": {
+			reason: "A shard service account that is deleted out of band should be restored.",
+			object: &corev1.ServiceAccount{},
+			mutate: func(ctx context.Context, c client.Client) error {
+				return c.Delete(ctx, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}})
+			},
+		},
+		"RoleChanged": {
+			reason: "A shard role that is changed out of band should be repaired.",
+			object: &rbacv1.Role{},
+			mutate: func(ctx context.Context, c client.Client) error {
+				r := &rbacv1.Role{}
+				if err := c.Get(ctx, key, r); err != nil {
+					return err
+				}
+				r.Rules = nil
+				return c.Update(ctx, r)
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			nn := networkNode(ndddvrv1.DeviceDriverKindGnmi, "10.0.0.1:57400", "")
+			c := newFakeClientApplicator(t)
+			h := NewDeviceDriverShardHooks(c, &fakeValidator{}, logging.NewNopLogger(), ndddvrv1.Namespace, false)
+
+			if err := h.Deploy(ctx, dd, shard, []ndddvrv1.Nn{nn}); err != nil {
+				t.Fatalf("Deploy(...): %v", err)
+			}
+			want := tc.object.DeepCopyObject().(client.Object)
+			if err := c.Get(ctx, key, want); err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+			deployed := &appsv1.Deployment{}
+			if err := c.Get(ctx, key, deployed); err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+
+			if err := tc.mutate(ctx, c); err != nil {
+				t.Fatalf("mutate(...): %v", err)
+			}
+			if err := h.Deploy(ctx, dd, shard, []ndddvrv1.Nn{nn}); err != nil {
+				t.Fatalf("Deploy(...): %v", err)
+			}
+
+			got := tc.object.DeepCopyObject().(client.Object)
+			if err := c.Get(ctx, key, got); err != nil {
+				t.Fatalf("\n%s\nGet(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(want, got, ignoreResourceVersion); diff != "" {
+				t.Errorf("\n%s\nDeploy(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			current := &appsv1.Deployment{}
+			if err := c.Get(ctx, key, current); err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+			if current.GetResourceVersion() != deployed.GetResourceVersion() {
+				t.Errorf("\n%s\nDeploy(...): the unchanged shard deployment was applied", tc.reason)
+			}
+		})
+	}
+}
@@ -19,9 +19,12 @@ package nn
 import (
 	"context"
 
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/controllers/rbac/roles"
 	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
 	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 )
 
 // A Creds validates the validaty of various resources.
@@ -29,18 +32,41 @@ type Validator interface {
 	// Validates the credentials
 	ValidateCredentials(ctx context.Context, namespace, credentialsName, targetAddress string) (*Credentials, error)
 
+	// Validates the proxy and its credentials
+	ValidateProxy(ctx context.Context, namespace, proxy, proxyCredentialsName string) (*Credentials, error)
+
+	// Validates the tls credentials
+	ValidateTLSCredentials(ctx context.Context, namespace, tlsCredentialsName string) (*TLSCredentials, error)
+
+	// Validates the reachability of the network node, with a protocol
+	// handshake when handshake is set
+	ValidateReachability(ctx context.Context, nn ndddvrv1.Nn, creds *Credentials, tls *TLSCredentials, handshake bool) (*Reachability, error)
+
+	// Selects the device driver of the network node
+	SelectDeviceDriver(ctx context.Context, nn ndddvrv1.Nn) (*ndddvrv1.DeviceDriver, error)
+
 	// Validates the device driver
-	ValidateDeviceDriver(ctx context.Context, namespace, name, kind string, port int) (*corev1.Container, error)
+	ValidateDeviceDriver(ctx context.Context, nn ndddvrv1.Nn, dd *ndddvrv1.DeviceDriver) (*corev1.PodTemplateSpec, error)
+
+	// Validates the device driver of a shard of a sharded device driver
+	ValidateShardDeviceDriver(ctx context.Context, dd *ndddvrv1.DeviceDriver, shard string) (*corev1.PodTemplateSpec, error)
+
+	// Validates the permissions of the device driver
+	ValidatePermissions(ctx context.Context, dd *ndddvrv1.DeviceDriver) ([]rbacv1.PolicyRule, error)
 }
 
 type NnValidator struct {
-	client resource.ClientApplicator
-	log    logging.Logger
+	client      resource.ClientApplicator
+	kinds       KindRegistry
+	permissions roles.PermissionRequestsValidator
+	log         logging.Logger
 }
 
-func NewNnValidator(client resource.ClientApplicator, log logging.Logger) *NnValidator {
+func NewNnValidator(client resource.ClientApplicator, kinds KindRegistry, permissions roles.PermissionRequestsValidator, log logging.Logger) *NnValidator {
 	return &NnValidator{
-		client: client,
-		log:    log,
+		client:      client,
+		kinds:       kinds,
+		permissions: permissions,
+		log:         log,
 	}
 }
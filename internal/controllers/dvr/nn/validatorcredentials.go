@@ -20,6 +20,7 @@ import (
 	"context"
 	"strings"
 
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
 	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -39,6 +40,9 @@ const (
 type Credentials struct {
 	Username string
 	Password string
+
+	// Proxy holds the credentials for authenticating with the proxy
+	Proxy *Credentials
 }
 
 func (v *NnValidator) ValidateCredentials(ctx context.Context, namespace, credentialsName, targetAddress string) (creds *Credentials, err error) {
@@ -46,7 +50,7 @@ func (v *NnValidator) ValidateCredentials(ctx context.Context, namespace, creden
 	log.Debug("Credentials Validation")
 	// Retrieve the secret from Kubernetes for this network node
 	if namespace == "" {
-		namespace = "default"
+		namespace = ndddvrv1.DefaultNamespace
 	}
 	credsSecret, err := v.GetSecret(ctx, namespace, credentialsName)
 	if err != nil {
@@ -59,8 +63,8 @@ func (v *NnValidator) ValidateCredentials(ctx context.Context, namespace, creden
 	}
 
 	creds = &Credentials{
-		Username: strings.TrimSuffix(string(credsSecret.Data["username"]), "\n"),
-		Password: strings.TrimSuffix(string(credsSecret.Data["password"]), "\n"),
+		Username: strings.TrimSuffix(string(credsSecret.Data[ndddvrv1.CredentialsUsernameKey]), "\n"),
+		Password: strings.TrimSuffix(string(credsSecret.Data[ndddvrv1.CredentialsPasswordKey]), "\n"),
 	}
 
 	log.Debug("Credentials", "creds", creds)
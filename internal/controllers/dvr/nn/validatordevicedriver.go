@@ -19,106 +19,289 @@ package nn
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"text/template"
 
-	dvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
 	// Errors
-	errFailedListDeviceDrivers = "failed to list device drivers"
+	errFailedListDeviceDrivers  = "failed to list device drivers"
+	errGetDeviceDriver          = "cannot get referenced device driver"
+	errDeviceDriverKindMismatch = "referenced device driver is of another device driver kind"
+	errDeviceDriverSelector     = "cannot parse device driver selector"
+	errNoSelectedDeviceDriver   = "no device driver matches the device driver selector"
+	errConflictingDeviceDrivers = "conflicting device drivers"
+	errRenderTemplate           = "cannot render device driver template"
 )
 
-func (v *NnValidator) ValidateDeviceDriver(ctx context.Context, namespace, name, kind string, port int) (c *corev1.Container, err error) {
-	log := v.log.WithValues("namespace", namespace, "name", name, "kind", kind, "port", port)
-	log.Debug("ValidateDeviceDriver")
+// SelectDeviceDriver selects the device driver of the network node with the
+// following precedence:
+// 1. the device driver referenced by the network node
+// 2. the device driver of the device driver kind matching the device driver
+// selector of the network node
+// 3. the device driver of the device driver kind marked as default
+// 4. the only device driver of the device driver kind
+// When none of them apply nil is returned and the built-in device driver is
+// used. Multiple device drivers matching at the same step are reported as a
+// conflict instead of picking one of them. The selector of the network node
+// takes precedence over the default device driver, since the selector is
+// specific to the network node while the default applies to all network nodes
+// of the device driver kind.
+func (v *NnValidator) SelectDeviceDriver(ctx context.Context, nn ndddvrv1.Nn) (*ndddvrv1.DeviceDriver, error) {
+	kind := string(nn.GetDeviceDriverKind())
+	log := v.log.WithValues("name", nn.GetName(), "kind", kind)
+	log.Debug("SelectDeviceDriver")
 
-	// environment parameters used in the device driver
-	envNameSpace := corev1.EnvVar{
-		Name: "POD_NAMESPACE",
-		ValueFrom: &corev1.EnvVarSource{
-			FieldRef: &corev1.ObjectFieldSelector{
-				APIVersion: "v1",
-				FieldPath:  "metadata.namespace",
-			},
-		},
+	if ref := nn.GetDeviceDriverReference(); ref != nil {
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = ndddvrv1.DefaultNamespace
+		}
+		dd := &ndddvrv1.DeviceDriver{}
+		if err := v.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, dd); err != nil {
+			return nil, errors.Wrap(err, errGetDeviceDriver)
+		}
+		if k, ok := dd.GetLabels()[ndddvrv1.LabelDeviceDriverKind]; ok && k != kind {
+			return nil, errors.Errorf("%s: %s", errDeviceDriverKindMismatch, k)
+		}
+		return dd, nil
 	}
-	envPodIP := corev1.EnvVar{
-		Name: "POD_IP",
-		ValueFrom: &corev1.EnvVarSource{
-			FieldRef: &corev1.ObjectFieldSelector{
-				APIVersion: "v1",
-				FieldPath:  "status.podIP",
-			},
-		},
+
+	dds := &ndddvrv1.DeviceDriverList{}
+	if err := v.client.List(ctx, dds, client.MatchingLabels{ndddvrv1.LabelDeviceDriverKind: kind}); err != nil {
+		return nil, errors.Wrap(err, errFailedListDeviceDrivers)
+	}
+
+	if ls := nn.GetDeviceDriverSelector(); ls != nil {
+		sel, err := metav1.LabelSelectorAsSelector(ls)
+		if err != nil {
+			return nil, errors.Wrap(err, errDeviceDriverSelector)
+		}
+		matches := filterDeviceDrivers(dds.Items, func(dd ndddvrv1.DeviceDriver) bool {
+			return sel.Matches(labels.Set(dd.GetLabels()))
+		})
+		if len(matches) == 0 {
+			return nil, errors.New(errNoSelectedDeviceDriver)
+		}
+		return uniqueDeviceDriver(matches)
+	}
+
+	defaults := filterDeviceDrivers(dds.Items, isDefaultDeviceDriver)
+	if len(defaults) > 0 {
+		return uniqueDeviceDriver(defaults)
+	}
+	if len(dds.Items) > 0 {
+		return uniqueDeviceDriver(dds.Items)
 	}
+	return nil, nil
+}
+
+// isDefaultDeviceDriver returns true if the device driver is marked as the
+// default device driver of its device driver kind.
+func isDefaultDeviceDriver(dd ndddvrv1.DeviceDriver) bool {
+	return dd.GetAnnotations()[ndddvrv1.AnnotationDefaultDriver] == "true"
+}
+
+func filterDeviceDrivers(dds []ndddvrv1.DeviceDriver, f func(dd ndddvrv1.DeviceDriver) bool) []ndddvrv1.DeviceDriver {
+	matches := make([]ndddvrv1.DeviceDriver, 0, len(dds))
+	for _, dd := range dds {
+		if f(dd) {
+			matches = append(matches, dd)
+		}
+	}
+	return matches
+}
+
+// uniqueDeviceDriver returns the device driver if there is exactly one and
+// reports a conflict listing all device drivers otherwise.
+func uniqueDeviceDriver(dds []ndddvrv1.DeviceDriver) (*ndddvrv1.DeviceDriver, error) {
+	if len(dds) == 1 {
+		return &dds[0], nil
+	}
+	names := make([]string, 0, len(dds))
+	for _, dd := range dds {
+		names = append(names, dd.GetNamespace()+"/"+dd.GetName())
+	}
+	sort.Strings(names)
+	return nil, errors.Errorf("%s: %s", errConflictingDeviceDrivers, strings.Join(names, ", "))
+}
+
+// deviceDriverTemplateData holds the placeholders of the arg and env templates
+// of the device driver container.
+type deviceDriverTemplateData struct {
+	NetworkNodeName string
+	Namespace       string
+	GrpcServerPort  int
+	TargetAddress   string
+	Encoding        string
+}
+
+// renderTemplate renders the template with the placeholders of the network node.
+func renderTemplate(tmpl string, data *deviceDriverTemplateData) (string, error) {
+	t, err := template.New("").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", errors.Wrap(err, errRenderTemplate)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", errors.Wrap(err, errRenderTemplate)
+	}
+	return b.String(), nil
+}
 
-	// list device drivers to see if there is specific device driver information
+// ValidateDeviceDriver returns the pod template of the device driver for the
+// network node with the device driver container as the first container. When no
+// device driver is selected the built-in device driver is used.
+func (v *NnValidator) ValidateDeviceDriver(ctx context.Context, nn ndddvrv1.Nn, dd *ndddvrv1.DeviceDriver) (*corev1.PodTemplateSpec, error) {
+	namespace := nn.GetNamespace()
 	if namespace == "" {
-		namespace = "default"
+		namespace = ndddvrv1.DefaultNamespace
 	}
-	selectors := []client.ListOption{
-		client.MatchingLabels{
-			"ddriver-kind": kind,
-		},
+	data := &deviceDriverTemplateData{
+		NetworkNodeName: nn.GetName(),
+		Namespace:       namespace,
+		GrpcServerPort:  nn.GetGrpcServerPort(),
+		TargetAddress:   nn.GetTargetAddress(),
+		Encoding:        nn.GetTargetEncoding(),
 	}
-	dds := &dvrv1.DeviceDriverList{}
-	if err := v.client.List(ctx, dds, selectors...); err != nil {
-		return nil, errors.Wrap(err, errFailedListDeviceDrivers)
+	log := v.log.WithValues("namespace", namespace, "name", data.NetworkNodeName, "port", data.GrpcServerPort)
+	log.Debug("ValidateDeviceDriver")
+	return v.podTemplate(ctx, nn.GetDeviceDriverKind(), dd, data)
+}
+
+// ValidateShardDeviceDriver returns the pod template of a shard of the sharded
+// device driver with the device driver container as the first container. The
+// templates of the device driver are rendered with the name of the shard as
+// the network node name and without target details.
+func (v *NnValidator) ValidateShardDeviceDriver(ctx context.Context, dd *ndddvrv1.DeviceDriver, shard string) (*corev1.PodTemplateSpec, error) {
+	kind, ok := dd.GetLabels()[ndddvrv1.LabelDeviceDriverKind]
+	if !ok {
+		kind = string(ndddvrv1.DeviceDriverKindGnmi)
 	}
-	for _, dd := range dds.Items {
-		c = dd.Spec.Container
+	data := &deviceDriverTemplateData{
+		NetworkNodeName: shard,
+		Namespace:       dd.GetNamespace(),
+		GrpcServerPort:  shardGrpcServerPort(dd),
+	}
+	v.log.Debug("ValidateShardDeviceDriver", "namespace", dd.GetNamespace(), "name", dd.GetName(), "shard", shard)
+	return v.podTemplate(ctx, ndddvrv1.DeviceDriverKind(kind), dd, data)
+}
+
+// podTemplate returns the pod template of the device driver rendered with the
+// template data, the defaults of the device driver kind are used when no device
+// driver container is specified. The pod runs with the secure default security
+// contexts unless the device driver overrides them.
+func (v *NnValidator) podTemplate(ctx context.Context, kind ndddvrv1.DeviceDriverKind, dd *ndddvrv1.DeviceDriver, data *deviceDriverTemplateData) (*corev1.PodTemplateSpec, error) {
+	log := v.log.WithValues("namespace", data.Namespace, "name", data.NetworkNodeName, "port", data.GrpcServerPort)
+
+	// the device driver container is the container of the device driver when
+	// specified, otherwise the first container of the pod template
+	pt := &corev1.PodTemplateSpec{}
+	var c *corev1.Container
+	var sidecars []corev1.Container
+	debug := false
+	if dd != nil {
+		if dd.Spec.PodTemplate != nil {
+			pt = dd.Spec.PodTemplate.DeepCopy()
+			sidecars = pt.Spec.Containers
+		}
+		switch {
+		case dd.Spec.Container != nil:
+			c = dd.Spec.Container.DeepCopy()
+		case len(sidecars) > 0:
+			c = &sidecars[0]
+			sidecars = sidecars[1:]
+		}
+		if dd.Spec.Debug != nil {
+			debug = *dd.Spec.Debug
+		}
 	}
 	if c == nil {
 		log.Debug("Using the default device driver configuration")
-		// apply the default settings
+		// apply the default settings of the device driver kind
+		kd, err := v.kinds.Get(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
 		c = &corev1.Container{
-			Name:            "nddriver-" + name,
-			Image:           "yndd/ndd-gnmi:latest",
-			ImagePullPolicy: corev1.PullAlways,
-			//ImagePullPolicy: corev1.PullIfNotPresent,
-			Args: []string{
-				"start",
-				"--grpc-server-address=" + ":" + fmt.Sprintf("%d", port),
-				"--device-name=" + name,
-				"--namespace=" + namespace,
-				"--debug",
-			},
-			Env: []corev1.EnvVar{
-				envNameSpace,
-				envPodIP,
-			},
-			Command: []string{
-				"/ddriver",
-			},
-			Resources: corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceCPU:    resource.MustParse("20m"),
-					corev1.ResourceMemory: resource.MustParse("32Mi"),
-				},
-				Limits: corev1.ResourceList{
-					corev1.ResourceCPU:    resource.MustParse("250m"),
-					corev1.ResourceMemory: resource.MustParse("256Mi"),
-				},
-			},
+			Name:            "nddriver-" + data.NetworkNodeName,
+			Image:           kd.Image,
+			ImagePullPolicy: kd.ImagePullPolicy,
+			Command:         append([]string{}, kd.Command...),
+			Args:            append([]string{}, kd.Args...),
+			Resources:       *kd.Resources.DeepCopy(),
 		}
 	} else {
 		log.Debug("Using the specific device driver configuration")
-		// update the argument/environment information, since this is specific for the container deployment
-		c.Args = []string{
-			"start",
-			"--grpc-server-address=" + ":" + fmt.Sprintf("%d", port),
-			"--device-name=" + name,
-			"--namespace=" + namespace,
-			"--debug",
+	}
+
+	// the mandatory arguments are specific for the container deployment, the
+	// arguments of the device driver are rendered and appended to them
+	args := []string{
+		"start",
+		"--grpc-server-address=" + ":" + fmt.Sprintf("%d", data.GrpcServerPort),
+		"--device-name=" + data.NetworkNodeName,
+		"--namespace=" + data.Namespace,
+	}
+	if debug {
+		args = append(args, "--debug")
+	}
+	for _, tmpl := range c.Args {
+		arg, err := renderTemplate(tmpl, data)
+		if err != nil {
+			return nil, err
 		}
-		c.Env = []corev1.EnvVar{
-			envNameSpace,
-			envPodIP,
+		args = append(args, arg)
+	}
+	c.Args = args
+
+	// environment parameters used in the device driver
+	env := []corev1.EnvVar{
+		{
+			Name: "POD_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "metadata.namespace",
+				},
+			},
+		},
+		{
+			Name: "POD_IP",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "status.podIP",
+				},
+			},
+		},
+	}
+	for _, e := range c.Env {
+		// the mandatory environment parameters are owned by ndd
+		if e.Name == "POD_NAMESPACE" || e.Name == "POD_IP" {
+			continue
 		}
+		if e.ValueFrom == nil {
+			value, err := renderTemplate(e.Value, data)
+			if err != nil {
+				return nil, err
+			}
+			e.Value = value
+		}
+		env = append(env, e)
 	}
-	return c, nil
+	c.Env = env
+
+	pt.Spec.Containers = append([]corev1.Container{*c}, sidecars...)
+	securePodTemplate(pt, dd)
+	return pt, nil
 }
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"strings"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/controllers/rbac/roles"
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Errors
+	errValidatePermissions = "cannot validate device driver permissions"
+	errRejectedPermissions = "refusing to deploy device driver due to request for disallowed permissions"
+)
+
+// ValidatePermissions validates the permissions of the device driver against
+// the permissions device drivers may request and returns the permissions of
+// the device driver. No permissions are granted when any permission is
+// rejected, like the rbac manager does for the permission requests of the
+// providers.
+func (v *NnValidator) ValidatePermissions(ctx context.Context, dd *ndddvrv1.DeviceDriver) ([]rbacv1.PolicyRule, error) {
+	if dd == nil || len(dd.Spec.Permissions) == 0 {
+		return nil, nil
+	}
+	log := v.log.WithValues("deviceDriver", dd.GetNamespace()+"/"+dd.GetName())
+	log.Debug("Permissions Validation")
+
+	rejected, err := v.permissions.ValidatePermissionRequests(ctx, dd.Spec.Permissions...)
+	if err != nil {
+		return nil, errors.Wrap(err, errValidatePermissions)
+	}
+	if len(rejected) > 0 {
+		rules := make([]string, 0, len(rejected))
+		for _, rule := range rejected {
+			rules = append(rules, rule.String())
+		}
+		return nil, errors.Errorf("%s %s", errRejectedPermissions, strings.Join(rules, ", "))
+	}
+	return dd.Spec.Permissions, nil
+}
+
+// NewPermissionRequestsValidator returns the validator of the permissions of
+// the device drivers, the permissions are validated against the cluster role
+// that enumerates the permissions device drivers may request. All permissions
+// are rejected without such a cluster role.
+func NewPermissionRequestsValidator(c client.Client, allowClusterRole string) roles.PermissionRequestsValidator {
+	if allowClusterRole == "" {
+		return roles.PermissionRequestsValidatorFn(roles.VerySecureValidator)
+	}
+	return roles.NewClusterRoleBackedValidator(c, allowClusterRole)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Errors
+	errInvalidProxy            = "invalid proxy url"
+	errUnsupportedProxyScheme  = "unsupported proxy scheme, supported schemes are socks5, socks5h and http"
+	errMissingProxyHost        = "missing host:port in proxy url"
+	errProxyUserInfo           = "proxy url must not hold credentials, use the proxy credentials instead"
+	errProxyCredentialsNoProxy = "proxy credentials without proxy"
+)
+
+// ValidateProxy validates the proxy url of the network node and returns the
+// credentials of the proxy when the network node references them.
+func (v *NnValidator) ValidateProxy(ctx context.Context, namespace, proxy, proxyCredentialsName string) (*Credentials, error) {
+	log := v.log.WithValues("namespace", namespace, "proxy", proxy, "proxyCredentialsName", proxyCredentialsName)
+	log.Debug("Proxy Validation")
+
+	if proxy == "" {
+		if proxyCredentialsName != "" {
+			return nil, errors.New(errProxyCredentialsNoProxy)
+		}
+		return nil, nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, errors.Wrap(err, errInvalidProxy)
+	}
+	switch u.Scheme {
+	case "socks5", "socks5h", "http":
+	default:
+		return nil, errors.Errorf("%s: %s", errUnsupportedProxyScheme, u.Scheme)
+	}
+	if u.Hostname() == "" || u.Port() == "" {
+		return nil, errors.New(errMissingProxyHost)
+	}
+	if u.User != nil {
+		return nil, errors.New(errProxyUserInfo)
+	}
+
+	if proxyCredentialsName == "" {
+		return nil, nil
+	}
+	if namespace == "" {
+		namespace = ndddvrv1.DefaultNamespace
+	}
+	s, err := v.GetSecret(ctx, namespace, proxyCredentialsName)
+	if err != nil {
+		return nil, err
+	}
+	creds := &Credentials{
+		Username: strings.TrimSuffix(string(s.Data[ndddvrv1.CredentialsUsernameKey]), "\n"),
+		Password: strings.TrimSuffix(string(s.Data[ndddvrv1.CredentialsPasswordKey]), "\n"),
+	}
+	if creds.Username == "" {
+		return nil, errors.New(errMissingUsername)
+	}
+	if creds.Password == "" {
+		return nil, errors.New(errMissingPassword)
+	}
+	return creds, nil
+}
@@ -0,0 +1,385 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	reachabilityTimeout = 10 * time.Second
+
+	// netconfDelimiter is the end of message delimiter of the netconf 1.0
+	// framing used for the hello messages
+	netconfDelimiter = "]]>]]>"
+
+	// Errors
+	errDialTarget          = "cannot connect to target"
+	errDialProxy           = "cannot connect to proxy"
+	errProxyConnect        = "proxy refused the connection to the target"
+	errGnmiCapabilities    = "cannot get gnmi capabilities"
+	errNetconfSession      = "cannot open netconf session"
+	errNetconfHello        = "cannot exchange netconf hello"
+	errTLSCredentialsParse = "cannot use tls credentials"
+)
+
+// Reachability holds the result of the pre-flight check of a network node.
+type Reachability struct {
+	// Latency to open a tcp connection to the network node
+	Latency time.Duration
+
+	// Protocol used in the handshake with the network node, empty when only
+	// the tcp connection is checked
+	Protocol string
+
+	// Encodings supported by the network node, i.e. the gnmi encodings or the
+	// netconf base capabilities
+	Encodings []string
+}
+
+// String returns a human readable summary of the reachability.
+func (r *Reachability) String() string {
+	s := "latency " + r.Latency.Round(time.Millisecond).String()
+	if r.Protocol != "" {
+		s += ", " + r.Protocol + " handshake succeeded"
+	}
+	if len(r.Encodings) > 0 {
+		s += ", encodings " + strings.Join(r.Encodings, ",")
+	}
+	return s
+}
+
+// reachabilityHash returns the hash of the target, the credentials and the
+// proxy of the network node, which determines if the handshake with the
+// network node has to be repeated.
+func reachabilityHash(nn ndddvrv1.Nn, creds *Credentials, tlsCreds *TLSCredentials) string {
+	target := struct {
+		Kind       ndddvrv1.DeviceDriverKind
+		Address    string
+		Proxy      string
+		Insecure   bool
+		SkipVerify bool
+		Creds      *Credentials
+		TLSCreds   *TLSCredentials
+	}{
+		Kind:       nn.GetDeviceDriverKind(),
+		Address:    nn.GetTargetAddress(),
+		Proxy:      nn.GetTargetProxy(),
+		Insecure:   nn.GetTargetInsecure(),
+		SkipVerify: nn.GetTargetSkipVerify(),
+		Creds:      creds,
+		TLSCreds:   tlsCreds,
+	}
+	b, _ := json.Marshal(target) // nolint:errcheck
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+// ValidateReachability opens a tcp connection to the target of the network
+// node through its proxy. When handshake is set it also performs a gnmi
+// capabilities or netconf hello handshake for the gnmi and netconf device
+// driver kinds.
+func (v *NnValidator) ValidateReachability(ctx context.Context, nn ndddvrv1.Nn, creds *Credentials, tlsCreds *TLSCredentials, handshake bool) (*Reachability, error) {
+	log := v.log.WithValues("name", nn.GetName(), "address", nn.GetTargetAddress(), "proxy", nn.GetTargetProxy())
+	log.Debug("Reachability Validation")
+
+	ctx, cancel := context.WithTimeout(ctx, reachabilityTimeout)
+	defer cancel()
+
+	var proxyCreds *Credentials
+	if creds != nil {
+		proxyCreds = creds.Proxy
+	}
+	dial := func(ctx context.Context, address string) (net.Conn, error) {
+		return dialTarget(ctx, nn.GetTargetProxy(), proxyCreds, address)
+	}
+
+	start := time.Now()
+	conn, err := dial(ctx, nn.GetTargetAddress())
+	if err != nil {
+		return nil, err
+	}
+	r := &Reachability{Latency: time.Since(start)}
+	conn.Close() // nolint:errcheck
+
+	if !handshake {
+		log.Debug("Reachability", "result", r.String())
+		return r, nil
+	}
+	switch nn.GetDeviceDriverKind() {
+	case ndddvrv1.DeviceDriverKindGnmi:
+		r.Protocol = string(ndddvrv1.DeviceDriverKindGnmi)
+		r.Encodings, err = gnmiCapabilities(ctx, nn, creds, tlsCreds, dial)
+	case ndddvrv1.DeviceDriverKindNetconf:
+		r.Protocol = string(ndddvrv1.DeviceDriverKindNetconf)
+		r.Encodings, err = netconfHello(ctx, nn, creds, dial)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("Reachability", "result", r.String())
+	return r, nil
+}
+
+// dialTarget opens a tcp connection to the address, through the socks5 or
+// http CONNECT proxy when specified.
+func dialTarget(ctx context.Context, proxyURL string, proxyCreds *Credentials, address string) (net.Conn, error) {
+	d := &net.Dialer{}
+	if proxyURL == "" {
+		conn, err := d.DialContext(ctx, "tcp", address)
+		return conn, errors.Wrap(err, errDialTarget)
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, errors.Wrap(err, errInvalidProxy)
+	}
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if proxyCreds != nil {
+			auth = &proxy.Auth{User: proxyCreds.Username, Password: proxyCreds.Password}
+		}
+		pd, err := proxy.SOCKS5("tcp", u.Host, auth, d)
+		if err != nil {
+			return nil, errors.Wrap(err, errDialProxy)
+		}
+		cd, ok := pd.(proxy.ContextDialer)
+		if !ok {
+			return nil, errors.New(errDialProxy)
+		}
+		conn, err := cd.DialContext(ctx, "tcp", address)
+		return conn, errors.Wrap(err, errDialTarget)
+	case "http":
+		return dialHTTPConnect(ctx, d, u.Host, proxyCreds, address)
+	}
+	return nil, errors.Errorf("%s: %s", errUnsupportedProxyScheme, u.Scheme)
+}
+
+// dialHTTPConnect opens a tcp connection to the address through a http
+// CONNECT proxy.
+func dialHTTPConnect(ctx context.Context, d *net.Dialer, proxyAddress string, proxyCreds *Credentials, address string) (net.Conn, error) {
+	conn, err := d.DialContext(ctx, "tcp", proxyAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, errDialProxy)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)          // nolint:errcheck
+		defer conn.SetDeadline(time.Time{}) // nolint:errcheck
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if proxyCreds != nil {
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(proxyCreds.Username+":"+proxyCreds.Password)))
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close() // nolint:errcheck
+		return nil, errors.Wrap(err, errDialProxy)
+	}
+	br := bufio.NewReader(conn)
+	rsp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close() // nolint:errcheck
+		return nil, errors.Wrap(err, errDialProxy)
+	}
+	rsp.Body.Close() // nolint:errcheck
+	if rsp.StatusCode != http.StatusOK {
+		conn.Close() // nolint:errcheck
+		return nil, errors.Errorf("%s: %s", errProxyConnect, rsp.Status)
+	}
+	// the target may already have sent data, e.g. the ssh banner, which is
+	// buffered in the reader
+	return &bufferedConn{Conn: conn, r: br}, nil
+}
+
+// bufferedConn is a connection that reads through a buffered reader.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// gnmiCapabilities returns the encodings supported by the gnmi target.
+func gnmiCapabilities(ctx context.Context, nn ndddvrv1.Nn, creds *Credentials, tlsCreds *TLSCredentials, dial func(ctx context.Context, address string) (net.Conn, error)) ([]string, error) {
+	opts := []grpc.DialOption{
+		grpc.WithBlock(),
+		grpc.WithContextDialer(dial),
+	}
+	if nn.GetTargetInsecure() {
+		opts = append(opts, grpc.WithInsecure())
+	} else {
+		cfg := &tls.Config{InsecureSkipVerify: nn.GetTargetSkipVerify()} // nolint:gosec
+		if tlsCreds != nil {
+			pool := x509.NewCertPool()
+			pool.AppendCertsFromPEM(tlsCreds.CA)
+			kp, err := tls.X509KeyPair(tlsCreds.Cert, tlsCreds.Key)
+			if err != nil {
+				return nil, errors.Wrap(err, errTLSCredentialsParse)
+			}
+			cfg.RootCAs = pool
+			cfg.Certificates = []tls.Certificate{kp}
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	}
+
+	conn, err := grpc.DialContext(ctx, nn.GetTargetAddress(), opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, errDialTarget)
+	}
+	defer conn.Close() // nolint:errcheck
+
+	if creds != nil {
+		ctx = metadata.AppendToOutgoingContext(ctx, "username", creds.Username, "password", creds.Password)
+	}
+	rsp, err := gnmi.NewGNMIClient(conn).Capabilities(ctx, &gnmi.CapabilityRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, errGnmiCapabilities)
+	}
+	encodings := make([]string, 0, len(rsp.GetSupportedEncodings()))
+	for _, e := range rsp.GetSupportedEncodings() {
+		encodings = append(encodings, e.String())
+	}
+	return encodings, nil
+}
+
+// netconfHello exchanges the netconf hello with the netconf target and
+// returns the netconf base capabilities of the target.
+func netconfHello(ctx context.Context, nn ndddvrv1.Nn, creds *Credentials, dial func(ctx context.Context, address string) (net.Conn, error)) ([]string, error) {
+	conn, err := dial(ctx, nn.GetTargetAddress())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close() // nolint:errcheck
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline) // nolint:errcheck
+	}
+
+	cfg := &ssh.ClientConfig{
+		// the pre-flight check has no known host keys to verify the target
+		// against, the device driver verifies the target
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // nolint:gosec
+	}
+	if creds != nil {
+		cfg.User = creds.Username
+		cfg.Auth = []ssh.AuthMethod{ssh.Password(creds.Password)}
+	}
+	sc, chans, reqs, err := ssh.NewClientConn(conn, nn.GetTargetAddress(), cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNetconfSession)
+	}
+	client := ssh.NewClient(sc, chans, reqs)
+	defer client.Close() // nolint:errcheck
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, errors.Wrap(err, errNetconfSession)
+	}
+	defer session.Close() // nolint:errcheck
+	w, err := session.StdinPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, errNetconfSession)
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, errNetconfSession)
+	}
+	if err := session.RequestSubsystem("netconf"); err != nil {
+		return nil, errors.Wrap(err, errNetconfSession)
+	}
+
+	msg, err := readNetconfMessage(r)
+	if err != nil {
+		return nil, errors.Wrap(err, errNetconfHello)
+	}
+	hello := struct {
+		Capabilities []string `xml:"capabilities>capability"`
+	}{}
+	if err := xml.Unmarshal(msg, &hello); err != nil {
+		return nil, errors.Wrap(err, errNetconfHello)
+	}
+	if _, err := io.WriteString(w, clientNetconfHello); err != nil {
+		return nil, errors.Wrap(err, errNetconfHello)
+	}
+
+	var base []string
+	for _, c := range hello.Capabilities {
+		c = strings.TrimSpace(c)
+		if strings.HasPrefix(c, "urn:ietf:params:netconf:base:") {
+			base = append(base, strings.TrimPrefix(c, "urn:ietf:params:netconf:"))
+		}
+	}
+	if len(base) == 0 {
+		return nil, errors.New(errNetconfHello + ": no netconf base capability")
+	}
+	return base, nil
+}
+
+// clientNetconfHello is the hello the pre-flight check sends to the target.
+var clientNetconfHello = fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<hello xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">
+  <capabilities>
+    <capability>urn:ietf:params:netconf:base:1.0</capability>
+    <capability>urn:ietf:params:netconf:base:1.1</capability>
+  </capabilities>
+</hello>%s`, netconfDelimiter)
+
+// readNetconfMessage reads a netconf 1.0 framed message.
+func readNetconfMessage(r io.Reader) ([]byte, error) {
+	var msg []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		msg = append(msg, buf[:n]...)
+		if i := bytes.Index(msg, []byte(netconfDelimiter)); i >= 0 {
+			return msg[:i], nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,400 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testUsername = "admin"
+	testPassword = "secret"
+)
+
+// listen opens a localhost listener that is closed at the end of the test.
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() }) // nolint:errcheck
+	return l
+}
+
+// pipe copies the data between both connections until one of them is closed.
+func pipe(a, b net.Conn) {
+	go func() {
+		io.Copy(a, b) // nolint:errcheck
+		a.Close()     // nolint:errcheck
+	}()
+	io.Copy(b, a) // nolint:errcheck
+	b.Close()     // nolint:errcheck
+}
+
+type fakeGnmiServer struct {
+	gnmi.UnimplementedGNMIServer
+}
+
+func (s *fakeGnmiServer) Capabilities(ctx context.Context, req *gnmi.CapabilityRequest) (*gnmi.CapabilityResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if u, p := md.Get("username"), md.Get("password"); len(u) != 1 || u[0] != testUsername || len(p) != 1 || p[0] != testPassword {
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+	return &gnmi.CapabilityResponse{
+		SupportedEncodings: []gnmi.Encoding{gnmi.Encoding_JSON, gnmi.Encoding_JSON_IETF},
+	}, nil
+}
+
+// startGnmiServer starts a gnmi server that only answers the capabilities rpc.
+func startGnmiServer(t *testing.T) string {
+	t.Helper()
+	l := listen(t)
+	s := grpc.NewServer()
+	gnmi.RegisterGNMIServer(s, &fakeGnmiServer{})
+	go s.Serve(l) // nolint:errcheck
+	t.Cleanup(s.Stop)
+	return l.Addr().String()
+}
+
+// startNetconfServer starts a ssh server that exchanges the netconf hello on
+// the netconf subsystem.
+func startNetconfServer(t *testing.T) string {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate host key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("host key signer: %v", err)
+	}
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if c.User() != testUsername || string(password) != testPassword {
+				return nil, fmt.Errorf("invalid credentials for %s", c.User())
+			}
+			return nil, nil
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	l := listen(t)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveNetconf(conn, cfg)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func serveNetconf(conn net.Conn, cfg *ssh.ServerConfig) {
+	sc, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	defer sc.Close() // nolint:errcheck
+	go ssh.DiscardRequests(reqs)
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			nc.Reject(ssh.UnknownChannelType, "unknown channel type") // nolint:errcheck
+			continue
+		}
+		ch, chReqs, err := nc.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer ch.Close() // nolint:errcheck
+			for req := range chReqs {
+				// the subsystem request holds the length prefixed subsystem name
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "netconf"
+				req.Reply(ok, nil) // nolint:errcheck
+				if !ok {
+					continue
+				}
+				hello := `<?xml version="1.0" encoding="UTF-8"?>
+<hello xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">
+  <capabilities>
+    <capability>urn:ietf:params:netconf:base:1.0</capability>
+    <capability>urn:ietf:params:netconf:base:1.1</capability>
+    <capability>urn:ietf:params:netconf:capability:candidate:1.0</capability>
+  </capabilities>
+  <session-id>1</session-id>
+</hello>` + netconfDelimiter
+				if _, err := io.WriteString(ch, hello); err != nil {
+					return
+				}
+				readNetconfMessage(ch) // nolint:errcheck
+				return
+			}
+		}()
+	}
+}
+
+// startSocks5Proxy starts a socks5 proxy without authentication that only
+// supports the CONNECT command.
+func startSocks5Proxy(t *testing.T) string {
+	t.Helper()
+	l := listen(t)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveSocks5(conn)
+		}
+	}()
+	return "socks5://" + l.Addr().String()
+}
+
+func serveSocks5(conn net.Conn) {
+	br := bufio.NewReader(conn)
+	// greeting: version, number of methods, methods
+	greeting := make([]byte, 2)
+	if _, err := io.ReadFull(br, greeting); err != nil || greeting[0] != 5 {
+		conn.Close() // nolint:errcheck
+		return
+	}
+	if _, err := io.ReadFull(br, make([]byte, greeting[1])); err != nil {
+		conn.Close() // nolint:errcheck
+		return
+	}
+	conn.Write([]byte{5, 0}) // nolint:errcheck
+
+	// request: version, command, reserved, address type, address, port
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(br, req); err != nil || req[1] != 1 {
+		conn.Close() // nolint:errcheck
+		return
+	}
+	var host string
+	switch req[3] {
+	case 1:
+		ip := make([]byte, 4)
+		if _, err := io.ReadFull(br, ip); err != nil {
+			conn.Close() // nolint:errcheck
+			return
+		}
+		host = net.IP(ip).String()
+	case 3:
+		n, err := br.ReadByte()
+		if err != nil {
+			conn.Close() // nolint:errcheck
+			return
+		}
+		name := make([]byte, n)
+		if _, err := io.ReadFull(br, name); err != nil {
+			conn.Close() // nolint:errcheck
+			return
+		}
+		host = string(name)
+	default:
+		conn.Close() // nolint:errcheck
+		return
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(br, port); err != nil {
+		conn.Close() // nolint:errcheck
+		return
+	}
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+	if err != nil {
+		conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0}) // nolint:errcheck
+		conn.Close()                                     // nolint:errcheck
+		return
+	}
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}) // nolint:errcheck
+	pipe(&bufferedConn{Conn: conn, r: br}, target)
+}
+
+// startHTTPConnectProxy starts a http proxy that only supports the CONNECT
+// method.
+func startHTTPConnectProxy(t *testing.T) string {
+	t.Helper()
+	l := listen(t)
+	s := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "only CONNECT is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, brw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			target.Close() // nolint:errcheck
+			return
+		}
+		brw.WriteString("HTTP/1.1 200 Connection established\r\n\r\n") // nolint:errcheck
+		brw.Flush()                                                    // nolint:errcheck
+		pipe(&bufferedConn{Conn: conn, r: brw.Reader}, target)
+	})}
+	go s.Serve(l)                   // nolint:errcheck
+	t.Cleanup(func() { s.Close() }) // nolint:errcheck
+	return "http://" + l.Addr().String()
+}
+
+func networkNode(kind ndddvrv1.DeviceDriverKind, address, proxy string) *ndddvrv1.NetworkNode {
+	insecure := true
+	nn := &ndddvrv1.NetworkNode{
+		Spec: ndddvrv1.NetworkNodeSpec{
+			DeviceDriverKind: &kind,
+			Target: &ndddvrv1.TargetDetails{
+				Address:  &address,
+				Insecure: &insecure,
+			},
+		},
+	}
+	nn.SetName("nn1")
+	if proxy != "" {
+		nn.Spec.Target.Proxy = &proxy
+	}
+	return nn
+}
+
+func TestValidateReachability(t *testing.T) {
+	gnmiAddress := startGnmiServer(t)
+	netconfAddress := startNetconfServer(t)
+	socks5Proxy := startSocks5Proxy(t)
+	httpProxy := startHTTPConnectProxy(t)
+
+	creds := &Credentials{Username: testUsername, Password: testPassword}
+
+	type args struct {
+		nn        ndddvrv1.Nn
+		creds     *Credentials
+		handshake bool
+	}
+	type want struct {
+		protocol  string
+		encodings []string
+		err       bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Gnmi": {
+			reason: "The gnmi capabilities handshake should return the gnmi encodings.",
+			args:   args{nn: networkNode(ndddvrv1.DeviceDriverKindGnmi, gnmiAddress, ""), creds: creds, handshake: true},
+			want:   want{protocol: "gnmi", encodings: []string{"JSON", "JSON_IETF"}},
+		},
+		"GnmiSocks5Proxy": {
+			reason: "The gnmi capabilities handshake should succeed through a socks5 proxy.",
+			args:   args{nn: networkNode(ndddvrv1.DeviceDriverKindGnmi, gnmiAddress, socks5Proxy), creds: creds, handshake: true},
+			want:   want{protocol: "gnmi", encodings: []string{"JSON", "JSON_IETF"}},
+		},
+		"GnmiHTTPConnectProxy": {
+			reason: "The gnmi capabilities handshake should succeed through a http CONNECT proxy.",
+			args:   args{nn: networkNode(ndddvrv1.DeviceDriverKindGnmi, gnmiAddress, httpProxy), creds: creds, handshake: true},
+			want:   want{protocol: "gnmi", encodings: []string{"JSON", "JSON_IETF"}},
+		},
+		"GnmiInvalidCredentials": {
+			reason: "The gnmi capabilities handshake should fail with invalid credentials.",
+			args:   args{nn: networkNode(ndddvrv1.DeviceDriverKindGnmi, gnmiAddress, ""), creds: &Credentials{Username: testUsername}, handshake: true},
+			want:   want{err: true},
+		},
+		"Netconf": {
+			reason: "The netconf hello should return the netconf base capabilities.",
+			args:   args{nn: networkNode(ndddvrv1.DeviceDriverKindNetconf, netconfAddress, ""), creds: creds, handshake: true},
+			want:   want{protocol: "netconf", encodings: []string{"base:1.0", "base:1.1"}},
+		},
+		"NetconfSocks5Proxy": {
+			reason: "The netconf hello should succeed through a socks5 proxy.",
+			args:   args{nn: networkNode(ndddvrv1.DeviceDriverKindNetconf, netconfAddress, socks5Proxy), creds: creds, handshake: true},
+			want:   want{protocol: "netconf", encodings: []string{"base:1.0", "base:1.1"}},
+		},
+		"NetconfHTTPConnectProxy": {
+			reason: "The netconf hello should succeed through a http CONNECT proxy.",
+			args:   args{nn: networkNode(ndddvrv1.DeviceDriverKindNetconf, netconfAddress, httpProxy), creds: creds, handshake: true},
+			want:   want{protocol: "netconf", encodings: []string{"base:1.0", "base:1.1"}},
+		},
+		"NetconfInvalidCredentials": {
+			reason: "The netconf session should fail with invalid credentials.",
+			args:   args{nn: networkNode(ndddvrv1.DeviceDriverKindNetconf, netconfAddress, ""), creds: &Credentials{Username: testUsername}, handshake: true},
+			want:   want{err: true},
+		},
+		"TCPOnly": {
+			reason: "Without handshake only the tcp connection should be checked.",
+			args:   args{nn: networkNode(ndddvrv1.DeviceDriverKindNetconf, gnmiAddress, socks5Proxy), creds: &Credentials{Username: testUsername}},
+			want:   want{},
+		},
+		"Unreachable": {
+			reason: "A target that refuses the tcp connection should be unreachable.",
+			args:   args{nn: networkNode(ndddvrv1.DeviceDriverKindGnmi, closedAddress(t), ""), creds: creds},
+			want:   want{err: true},
+		},
+	}
+
+	v := &NnValidator{log: logging.NewNopLogger()}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r, err := v.ValidateReachability(context.Background(), tc.args.nn, tc.args.creds, nil, tc.args.handshake)
+			if (err != nil) != tc.want.err {
+				t.Fatalf("\n%s\nValidateReachability(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.protocol, r.Protocol); diff != "" {
+				t.Errorf("\n%s\nValidateReachability(...): -want protocol, +got protocol:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.encodings, r.Encodings); diff != "" {
+				t.Errorf("\n%s\nValidateReachability(...): -want encodings, +got encodings:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// closedAddress returns the address of a listener that is already closed.
+func closedAddress(t *testing.T) string {
+	t.Helper()
+	l := listen(t)
+	address := l.Addr().String()
+	l.Close() // nolint:errcheck
+	return address
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Errors
+	errMissingTLSCA      = "missing " + ndddvrv1.TLSCAKey + " in tls credentials"
+	errMissingTLSCert    = "missing " + ndddvrv1.TLSCertKey + " in tls credentials"
+	errMissingTLSKey     = "missing " + ndddvrv1.TLSKeyKey + " in tls credentials"
+	errInvalidTLSCA      = "cannot parse " + ndddvrv1.TLSCAKey + " in tls credentials"
+	errInvalidTLSKeyPair = "cannot parse " + ndddvrv1.TLSCertKey + " and " + ndddvrv1.TLSKeyKey + " in tls credentials"
+)
+
+// TLSCredentials holds the tls material for authenticating with the Server.
+type TLSCredentials struct {
+	CA   []byte
+	Cert []byte
+	Key  []byte
+
+	// NotAfter is the expiry time of the certificate
+	NotAfter time.Time
+}
+
+// ValidateTLSCredentials validates the tls credentials secret of the network
+// node. The secret must hold the PEM encoded TLSCA, TLSCert and TLSKey keys,
+// the CA must be parseable and the key must match the certificate. The
+// returned credentials hold the expiry time of the certificate in NotAfter.
+func (v *NnValidator) ValidateTLSCredentials(ctx context.Context, namespace, tlsCredentialsName string) (*TLSCredentials, error) {
+	log := v.log.WithValues("namespace", namespace, "tlsCredentialsName", tlsCredentialsName)
+	log.Debug("TLS Credentials Validation")
+	if namespace == "" {
+		namespace = ndddvrv1.DefaultNamespace
+	}
+	tlsSecret, err := v.GetSecret(ctx, namespace, tlsCredentialsName)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := &TLSCredentials{
+		CA:   tlsSecret.Data[ndddvrv1.TLSCAKey],
+		Cert: tlsSecret.Data[ndddvrv1.TLSCertKey],
+		Key:  tlsSecret.Data[ndddvrv1.TLSKeyKey],
+	}
+	if len(creds.CA) == 0 {
+		return nil, errors.New(errMissingTLSCA)
+	}
+	if len(creds.Cert) == 0 {
+		return nil, errors.New(errMissingTLSCert)
+	}
+	if len(creds.Key) == 0 {
+		return nil, errors.New(errMissingTLSKey)
+	}
+
+	if !x509.NewCertPool().AppendCertsFromPEM(creds.CA) {
+		return nil, errors.New(errInvalidTLSCA)
+	}
+
+	// X509KeyPair also validates that the private key matches the public key
+	// of the certificate
+	kp, err := tls.X509KeyPair(creds.Cert, creds.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, errInvalidTLSKeyPair)
+	}
+	leaf, err := x509.ParseCertificate(kp.Certificate[0])
+	if err != nil {
+		return nil, errors.Wrap(err, errInvalidTLSKeyPair)
+	}
+	creds.NotAfter = leaf.NotAfter
+
+	log.Debug("TLS Credentials", "notAfter", creds.NotAfter)
+	return creds, nil
+}
@@ -18,8 +18,11 @@ package nn
 
 import (
 	"context"
+	"reflect"
+	"strings"
 
 	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
@@ -32,32 +35,39 @@ type adder interface {
 	Add(item interface{})
 }
 
+// EnqueueRequestForAllDeviceDriversWithRequests enqueues a request for the
+// network nodes bound to a device driver. When the device driver is created,
+// deleted or its selection labels change the network nodes of the device driver
+// kind without a device driver reference are enqueued as well, since their
+// device driver selection can change.
 type EnqueueRequestForAllDeviceDriversWithRequests struct {
 	client client.Client
 }
 
 // Create enqueues a request for all network nodes which pertains to the Device Driver.
 func (e *EnqueueRequestForAllDeviceDriversWithRequests) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
-	e.add(evt.Object, q)
+	e.add(evt.Object, q, true)
 }
 
 // Update enqueues a request for all network nodes which pertains to the Device Driver.
 func (e *EnqueueRequestForAllDeviceDriversWithRequests) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
-	e.add(evt.ObjectOld, q)
-	e.add(evt.ObjectNew, q)
+	reselect := !reflect.DeepEqual(evt.ObjectOld.GetLabels(), evt.ObjectNew.GetLabels()) ||
+		evt.ObjectOld.GetAnnotations()[ndddvrv1.AnnotationDefaultDriver] != evt.ObjectNew.GetAnnotations()[ndddvrv1.AnnotationDefaultDriver]
+	e.add(evt.ObjectOld, q, reselect)
+	e.add(evt.ObjectNew, q, reselect)
 }
 
 // Delete enqueues a request for all network nodes which pertains to the Device Driver.
 func (e *EnqueueRequestForAllDeviceDriversWithRequests) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
-	e.add(evt.Object, q)
+	e.add(evt.Object, q, true)
 }
 
 // Generic enqueues a request for all network nodes which pertains to the Device Driver.
 func (e *EnqueueRequestForAllDeviceDriversWithRequests) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
-	e.add(evt.Object, q)
+	e.add(evt.Object, q, false)
 }
 
-func (e *EnqueueRequestForAllDeviceDriversWithRequests) add(obj runtime.Object, queue adder) {
+func (e *EnqueueRequestForAllDeviceDriversWithRequests) add(obj runtime.Object, queue adder, reselect bool) {
 	dd, ok := obj.(*ndddvrv1.DeviceDriver)
 	if !ok {
 		return
@@ -69,13 +79,274 @@ func (e *EnqueueRequestForAllDeviceDriversWithRequests) add(obj runtime.Object,
 	}
 
 	for _, n := range nn.Items {
-		// only enqueue if the network node device driver kind matches with the device driver label
-		for k, v := range dd.GetLabels() {
-			if k == "ddriver-kind" {
-				if string(*n.Spec.DeviceDriverKind) == v {
-					queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: n.GetName()}})
-				}
+		switch {
+		case referencesDeviceDriver(n.GetBoundDeviceDriver(), dd), referencesDeviceDriver(n.GetDeviceDriverReference(), dd):
+			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: n.GetName()}})
+		case reselect && n.GetDeviceDriverReference() == nil:
+			// only enqueue if the network node device driver kind matches with the device driver label
+			if kind, ok := dd.GetLabels()[ndddvrv1.LabelDeviceDriverKind]; ok && string(n.GetDeviceDriverKind()) == kind {
+				queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: n.GetName()}})
 			}
 		}
 	}
 }
+
+// referencesDeviceDriver returns true if the reference points to the device
+// driver.
+func referencesDeviceDriver(ref *ndddvrv1.DeviceDriverReference, dd *ndddvrv1.DeviceDriver) bool {
+	if ref == nil {
+		return false
+	}
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = ndddvrv1.DefaultNamespace
+	}
+	return ref.Name == dd.GetName() && namespace == dd.GetNamespace()
+}
+
+// EnqueueRequestForReferencedSecrets enqueues a request for all network nodes
+// that reference a secret in their credentials, tls credentials or proxy
+// credentials, such that
+// rotated secrets are rolled out to the device drivers.
+type EnqueueRequestForReferencedSecrets struct {
+	client client.Client
+}
+
+// Create enqueues a request for all network nodes which reference the Secret.
+func (e *EnqueueRequestForReferencedSecrets) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Update enqueues a request for all network nodes which reference the Secret.
+func (e *EnqueueRequestForReferencedSecrets) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.ObjectOld, q)
+	e.add(evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for all network nodes which reference the Secret.
+func (e *EnqueueRequestForReferencedSecrets) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic enqueues a request for all network nodes which reference the Secret.
+func (e *EnqueueRequestForReferencedSecrets) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+func (e *EnqueueRequestForReferencedSecrets) add(obj runtime.Object, queue adder) {
+	s, ok := obj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+
+	nn := &ndddvrv1.NetworkNodeList{}
+	if err := e.client.List(context.TODO(), nn); err != nil {
+		return
+	}
+
+	for _, n := range nn.Items {
+		// the secrets of a network node are retrieved from the default namespace
+		// when the network node has no namespace
+		namespace := n.GetNamespace()
+		if namespace == "" {
+			namespace = ndddvrv1.DefaultNamespace
+		}
+		if s.GetNamespace() != namespace {
+			continue
+		}
+		if n.GetTargetCredentialsName() == s.GetName() || n.GetTargetTLSCredentialsName() == s.GetName() ||
+			n.GetTargetProxyCredentialsName() == s.GetName() {
+			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: n.GetName()}})
+		}
+	}
+}
+
+// EnqueueRequestForDeviceDriverPods enqueues a request for the network node of
+// a device driver pod, such that the health of the device driver is updated
+// when its pods fail.
+type EnqueueRequestForDeviceDriverPods struct {
+	namespace string
+}
+
+// Create enqueues a request for the network node of the Pod.
+func (e *EnqueueRequestForDeviceDriverPods) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Update enqueues a request for the network node of the Pod.
+func (e *EnqueueRequestForDeviceDriverPods) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for the network node of the Pod.
+func (e *EnqueueRequestForDeviceDriverPods) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic enqueues a request for the network node of the Pod.
+func (e *EnqueueRequestForDeviceDriverPods) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+func (e *EnqueueRequestForDeviceDriverPods) add(obj runtime.Object, queue adder) {
+	p, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	if p.GetNamespace() != e.namespace {
+		return
+	}
+	// the device driver pods are labeled with the name of the network node,
+	// the pods of the shards are labeled with their device driver and report
+	// their health to the device driver controller
+	if _, ok := p.GetLabels()[ndddvrv1.LabelDeviceDriverName]; ok {
+		return
+	}
+	app, ok := p.GetLabels()[ndddvrv1.LabelApplication]
+	if !ok || !strings.HasPrefix(app, ndddvrv1.PrefixNetworkNode+"-") {
+		return
+	}
+	queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: strings.TrimPrefix(app, ndddvrv1.PrefixNetworkNode+"-")}})
+}
+
+// EnqueueRequestForDeviceDriverKinds enqueues a request for all network nodes
+// without a device driver when the device driver kinds configmap changes, such
+// that the defaults of the device driver kinds are rolled out.
+type EnqueueRequestForDeviceDriverKinds struct {
+	client    client.Client
+	namespace string
+}
+
+// Create enqueues a request for all network nodes using the device driver kinds.
+func (e *EnqueueRequestForDeviceDriverKinds) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Update enqueues a request for all network nodes using the device driver kinds.
+func (e *EnqueueRequestForDeviceDriverKinds) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for all network nodes using the device driver kinds.
+func (e *EnqueueRequestForDeviceDriverKinds) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic enqueues a request for all network nodes using the device driver kinds.
+func (e *EnqueueRequestForDeviceDriverKinds) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+func (e *EnqueueRequestForDeviceDriverKinds) add(obj runtime.Object, queue adder) {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+	if cm.GetNamespace() != e.namespace || cm.GetName() != KindsConfigMap {
+		return
+	}
+
+	nn := &ndddvrv1.NetworkNodeList{}
+	if err := e.client.List(context.TODO(), nn); err != nil {
+		return
+	}
+
+	for _, n := range nn.Items {
+		if n.GetBoundDeviceDriver() == nil {
+			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: n.GetName()}})
+		}
+	}
+}
+
+// EnqueueRequestForShardMembers enqueues a request for the network nodes served
+// by a shard when the deployment or the pods of the shard change, such that the
+// health of the shard is reported on its network nodes.
+type EnqueueRequestForShardMembers struct {
+	client    client.Client
+	namespace string
+}
+
+// Create enqueues a request for the network nodes of the shard.
+func (e *EnqueueRequestForShardMembers) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Update enqueues a request for the network nodes of the shard.
+func (e *EnqueueRequestForShardMembers) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for the network nodes of the shard.
+func (e *EnqueueRequestForShardMembers) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic enqueues a request for the network nodes of the shard.
+func (e *EnqueueRequestForShardMembers) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+func (e *EnqueueRequestForShardMembers) add(obj runtime.Object, queue adder) {
+	o, ok := obj.(client.Object)
+	if !ok || o.GetNamespace() != e.namespace {
+		return
+	}
+	if _, ok := o.GetLabels()[ndddvrv1.LabelDeviceDriverName]; !ok {
+		return
+	}
+	shard := o.GetLabels()[ndddvrv1.LabelApplication]
+
+	nns := &ndddvrv1.NetworkNodeList{}
+	if err := e.client.List(context.TODO(), nns); err != nil {
+		return
+	}
+	for _, nn := range nns.Items {
+		if nn.GetAnnotations()[ndddvrv1.AnnotationShard] == shard {
+			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: nn.GetName()}})
+		}
+	}
+}
+
+// EnqueueRequestForNetworkNodeClassMembers enqueues a request for the network
+// nodes of a network node class when the class changes, such that the
+// defaults of the class are rolled out to its network nodes.
+type EnqueueRequestForNetworkNodeClassMembers struct {
+	client client.Client
+}
+
+// Create enqueues a request for the network nodes of the NetworkNodeClass.
+func (e *EnqueueRequestForNetworkNodeClassMembers) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Update enqueues a request for the network nodes of the NetworkNodeClass.
+func (e *EnqueueRequestForNetworkNodeClassMembers) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for the network nodes of the NetworkNodeClass.
+func (e *EnqueueRequestForNetworkNodeClassMembers) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic enqueues a request for the network nodes of the NetworkNodeClass.
+func (e *EnqueueRequestForNetworkNodeClassMembers) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+func (e *EnqueueRequestForNetworkNodeClassMembers) add(obj runtime.Object, queue adder) {
+	c, ok := obj.(*ndddvrv1.NetworkNodeClass)
+	if !ok {
+		return
+	}
+
+	nns := &ndddvrv1.NetworkNodeList{}
+	if err := e.client.List(context.TODO(), nns); err != nil {
+		return
+	}
+	for _, nn := range nns.Items {
+		if nn.GetNetworkNodeClassName() == c.GetName() {
+			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: nn.GetName()}})
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nn
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// fakeQueue records the requests added to it.
+type fakeQueue struct {
+	requests []interface{}
+}
+
+func (q *fakeQueue) Add(item interface{}) {
+	q.requests = append(q.requests, item)
+}
+
+func TestEnqueueRequestForDeviceDriverPods(t *testing.T) {
+	dd := &ndddvrv1.DeviceDriver{ObjectMeta: metav1.ObjectMeta{Namespace: ndddvrv1.DefaultNamespace, Name: "gnmi"}}
+
+	cases := map[string]struct {
+		reason string
+		labels map[string]string
+		want   []interface{}
+	}{
+		"NetworkNode": {
+			reason: "A device driver pod should enqueue its network node.",
+			labels: map[string]string{ndddvrv1.LabelApplication: "ndd-leaf1"},
+			want:   []interface{}{reconcile.Request{NamespacedName: types.NamespacedName{Name: "leaf1"}}},
+		},
+		"NetworkNodeNamedShard": {
+			reason: "A device driver pod of a network node named like a shard should enqueue its network node.",
+			labels: map[string]string{ndddvrv1.LabelApplication: "ndd-shard-leaf1"},
+			want:   []interface{}{reconcile.Request{NamespacedName: types.NamespacedName{Name: "shard-leaf1"}}},
+		},
+		"Shard": {
+			reason: "A shard pod should not enqueue a network node.",
+			labels: shardLabels(dd, ShardName(dd, 0)),
+		},
+		"Other": {
+			reason: "A pod that is not a device driver pod should not enqueue a network node.",
+			labels: map[string]string{ndddvrv1.LabelApplication: "other"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: ndddvrv1.Namespace, Name: "pod", Labels: tc.labels}}
+			q := &fakeQueue{}
+			e := &EnqueueRequestForDeviceDriverPods{namespace: ndddvrv1.Namespace}
+			e.add(p, q)
+			if diff := cmp.Diff(tc.want, q.requests); diff != "" {
+				t.Errorf("\n%s\nadd(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nnu
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
+	"github.com/netw-device-driver/ndd-runtime/pkg/event"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/meta"
+	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// Finalizer
+	finalizer = "in-use.dvr.ndd.yndd.io"
+
+	// networkNodeIndex indexes the network node usages by the name of the
+	// network node they reference
+	networkNodeIndex = ".NetworkNodeRef.name"
+
+	// Timers
+	reconcileTimeout = 1 * time.Minute
+	shortWait        = 30 * time.Second
+
+	// Errors
+	errIndexUsages     = "cannot index network node usages"
+	errGetNetworkNode  = "cannot get network node resource"
+	errListUsages      = "cannot list network node usages"
+	errUpdateStatus    = "cannot update network node status"
+	errAddFinalizer    = "cannot add network node usage finalizer"
+	errRemoveFinalizer = "cannot remove network node usage finalizer"
+	errMissingNode     = "network node usage references a network node that does not exist"
+
+	// Event reasons
+	reasonAccount event.Reason = "UsageAccounting"
+)
+
+// ReconcilerOption is used to configure the Reconciler.
+type ReconcilerOption func(*Reconciler)
+
+// WithLogger specifies how the Reconciler should log messages.
+func WithLogger(log logging.Logger) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.log = log
+	}
+}
+
+// WithRecorder specifies how the Reconciler should record Kubernetes events.
+func WithRecorder(er event.Recorder) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.record = er
+	}
+}
+
+// Reconciler accounts the network node usages of the network nodes and blocks
+// the deletion of the network nodes that are still in use.
+type Reconciler struct {
+	client      client.Client
+	nnFinalizer resource.Finalizer
+	log         logging.Logger
+	record      event.Recorder
+}
+
+// indexNetworkNode returns the name of the network node referenced by a network
+// node usage.
+func indexNetworkNode(o client.Object) []string {
+	u, ok := o.(*ndddvrv1.NetworkNodeUsage)
+	if !ok || u.GetNetworkNodeReference().Name == "" {
+		return nil
+	}
+	return []string{u.GetNetworkNodeReference().Name}
+}
+
+// Setup adds a controller that reconciles the network node usages.
+func Setup(mgr ctrl.Manager, l logging.Logger, namespace string) error {
+	name := "dvr/" + strings.ToLower(ndddvrv1.NetworkNodeUsageKind)
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &ndddvrv1.NetworkNodeUsage{}, networkNodeIndex, indexNetworkNode); err != nil {
+		return errors.Wrap(err, errIndexUsages)
+	}
+
+	r := NewReconciler(mgr,
+		WithLogger(l.WithValues("controller", name)),
+		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+	)
+
+	// the network nodes are reconciled when they are deleted or when the force
+	// delete annotation is set, the usages are reconciled by the network node
+	// they reference
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&ndddvrv1.NetworkNode{}, builder.WithPredicates(predicate.Or(
+			resource.IgnoreUpdateWithoutGenerationChangePredicate(),
+			predicate.AnnotationChangedPredicate{},
+		))).
+		Watches(&source.Kind{Type: &ndddvrv1.NetworkNodeUsage{}}, &EnqueueRequestForNetworkNodeUsages{}).
+		Complete(r)
+}
+
+// NewReconciler creates a new network node usage reconciler.
+func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
+	r := &Reconciler{
+		client:      mgr.GetClient(),
+		nnFinalizer: resource.NewAPIFinalizer(mgr.GetClient(), finalizer),
+		log:         logging.NewNopLogger(),
+		record:      event.NewNopRecorder(),
+	}
+
+	for _, f := range opts {
+		f(r)
+	}
+
+	return r
+}
+
+// usedBy returns the sorted references of the resources using the network node.
+func usedBy(l *ndddvrv1.NetworkNodeUsageList) []nddv1.TypedReference {
+	refs := make([]nddv1.TypedReference, 0, len(l.Items))
+	for _, u := range l.Items {
+		refs = append(refs, u.GetResourceReference())
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Kind != refs[j].Kind {
+			return refs[i].Kind < refs[j].Kind
+		}
+		return refs[i].Name < refs[j].Name
+	})
+	return refs
+}
+
+// isForceDeleted returns true if the network node may be deleted while it is
+// still in use.
+func isForceDeleted(nn ndddvrv1.Nn) bool {
+	force, err := strconv.ParseBool(nn.GetAnnotations()[ndddvrv1.AnnotationForceDelete])
+	return err == nil && force
+}
+
+// +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=networknodeusages,verbs=get;list;watch
+// +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=networknodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=dvr.ndd.yndd.io,resources=networknodes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=list;watch;get;patch;create;update;delete
+
+// Reconcile the usages of a network node.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Network Node Usage", "NameSpace", req.NamespacedName)
+
+	ctx, cancel := context.WithTimeout(ctx, reconcileTimeout)
+	defer cancel()
+
+	l := &ndddvrv1.NetworkNodeUsageList{}
+	if err := r.client.List(ctx, l, client.MatchingFields{networkNodeIndex: req.Name}); err != nil {
+		log.Debug(errListUsages, "error", err)
+		return reconcile.Result{}, errors.Wrap(err, errListUsages)
+	}
+
+	nn := &ndddvrv1.NetworkNode{}
+	if err := r.client.Get(ctx, req.NamespacedName, nn); err != nil {
+		if !kerrors.IsNotFound(err) {
+			log.Debug(errGetNetworkNode, "error", err)
+			return reconcile.Result{}, errors.Wrap(err, errGetNetworkNode)
+		}
+		// the usages of a network node that does not exist are reported, they
+		// are accounted when the network node gets created
+		for i := range l.Items {
+			log.Debug(errMissingNode, "usage", l.Items[i].GetName())
+			r.record.Event(&l.Items[i], event.Warning(reasonAccount, errors.Errorf("%s: %s", errMissingNode, req.Name)))
+		}
+		return reconcile.Result{}, nil
+	}
+
+	users := usedBy(l)
+	log = log.WithValues("usages", len(users))
+
+	if meta.WasDeleted(nn) {
+		if len(users) > 0 {
+			if !isForceDeleted(nn) {
+				msg := "blocking deletion while usages still exist"
+				log.Debug(msg)
+				r.record.Event(nn, event.Warning(reasonAccount, errors.New(msg)))
+
+				// the usages are watched, so we'll be requeued when they go
+				nn.SetUsers(int64(len(users)))
+				nn.SetUsedBy(users)
+				nn.SetConditions(ndddvrv1.Terminating().WithMessage(msg))
+				return reconcile.Result{Requeue: false}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+			}
+			r.record.Event(nn, event.Warning(reasonAccount, errors.Errorf("force deleted while in use by %d resources", len(users))))
+		}
+
+		if err := r.nnFinalizer.RemoveFinalizer(ctx, nn); err != nil {
+			log.Debug(errRemoveFinalizer, "error", err)
+			r.record.Event(nn, event.Warning(reasonAccount, errors.Wrap(err, errRemoveFinalizer)))
+			return reconcile.Result{RequeueAfter: shortWait}, nil
+		}
+		return reconcile.Result{Requeue: false}, nil
+	}
+
+	if err := r.nnFinalizer.AddFinalizer(ctx, nn); err != nil {
+		log.Debug(errAddFinalizer, "error", err)
+		r.record.Event(nn, event.Warning(reasonAccount, errors.Wrap(err, errAddFinalizer)))
+		return reconcile.Result{RequeueAfter: shortWait}, nil
+	}
+
+	// there's no need to requeue explicitly, the usages are watched
+	nn.SetUsers(int64(len(users)))
+	nn.SetUsedBy(users)
+	return reconcile.Result{Requeue: false}, errors.Wrap(r.client.Status().Update(ctx, nn), errUpdateStatus)
+}
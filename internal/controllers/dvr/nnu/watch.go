@@ -0,0 +1,66 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nnu
+
+import (
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type adder interface {
+	Add(item interface{})
+}
+
+// EnqueueRequestForNetworkNodeUsages enqueues a request for the network node
+// referenced by a network node usage.
+type EnqueueRequestForNetworkNodeUsages struct{}
+
+// Create enqueues a request for the network node of the NetworkNodeUsage.
+func (e *EnqueueRequestForNetworkNodeUsages) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Update enqueues a request for the network nodes of the NetworkNodeUsage, the
+// usage can move from one network node to another.
+func (e *EnqueueRequestForNetworkNodeUsages) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.ObjectOld, q)
+	e.add(evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for the network node of the NetworkNodeUsage.
+func (e *EnqueueRequestForNetworkNodeUsages) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic enqueues a request for the network node of the NetworkNodeUsage.
+func (e *EnqueueRequestForNetworkNodeUsages) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+func (e *EnqueueRequestForNetworkNodeUsages) add(obj runtime.Object, queue adder) {
+	u, ok := obj.(*ndddvrv1.NetworkNodeUsage)
+	if !ok {
+		return
+	}
+	if name := u.GetNetworkNodeReference().Name; name != "" {
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+	}
+}
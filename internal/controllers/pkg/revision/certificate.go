@@ -0,0 +1,99 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/netw-device-driver/ndd-core/apis/pkg/v1"
+	"github.com/netw-device-driver/ndd-core/internal/pki"
+	"github.com/netw-device-driver/ndd-runtime/pkg/meta"
+	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	errGetClientCert    = "cannot get provider client certificate"
+	errIssueClientCert  = "cannot issue provider client certificate"
+	errApplyClientCert  = "cannot apply provider client certificate"
+	errDeleteClientCert = "cannot delete provider client certificate"
+)
+
+// serviceAccountName returns the name of the service account the packaged
+// controller runs with.
+func serviceAccountName(revision v1.PackageRevision, cc *v1.ControllerConfig) string {
+	if cc != nil && cc.Spec.ServiceAccountName != nil {
+		return *cc.Spec.ServiceAccountName
+	}
+	return revision.GetName()
+}
+
+// emptyClientCertSecret returns the secret holding the client certificate of
+// the packaged controller without data.
+func emptyClientCertSecret(revision v1.PackageRevision, namespace string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            strings.Join([]string{revision.GetName(), v1.SuffixClientCertSecret}, "-"),
+			Namespace:       namespace,
+			OwnerReferences: []metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(revision, v1.ProviderRevisionGroupVersionKind))},
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+}
+
+// buildClientCertSecret builds the secret holding the client certificate of the
+// service account of the packaged controller, with which the controller
+// authenticates to the device drivers. The certificate in the current secret is
+// kept as long as it is valid, otherwise a new certificate is issued by the
+// certificate authority.
+func buildClientCertSecret(ctx context.Context, c client.Reader, ca *pki.CA, revision v1.PackageRevision, cc *v1.ControllerConfig, namespace string) (*corev1.Secret, error) {
+	s := emptyClientCertSecret(revision, namespace)
+	current := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: s.GetNamespace(), Name: s.GetName()}, current)
+	if resource.IgnoreNotFound(err) != nil {
+		return nil, errors.Wrap(err, errGetClientCert)
+	}
+	cn := strings.Join([]string{"system", "serviceaccount", namespace, serviceAccountName(revision, cc)}, ":")
+	if s.Data, err = ca.ClientCertificate(current.Data, cn); err != nil {
+		return nil, errors.Wrap(err, errIssueClientCert)
+	}
+	return s, nil
+}
+
+// clientCertHash returns the hash of the client certificate, which is used to
+// roll the pod of the packaged controller when the certificate is renewed.
+func clientCertHash(s *corev1.Secret) string {
+	keys := make([]string, 0, len(s.Data))
+	for k := range s.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k)) // nolint:errcheck
+		h.Write(s.Data[k]) // nolint:errcheck
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -35,7 +35,9 @@ var (
 	runAsNonRoot             = true
 )
 
-func buildProviderDeployment(provider *pkgmetav1.Provider, revision v1.PackageRevision, cc *v1.ControllerConfig, namespace string) (*corev1.ServiceAccount, *appsv1.Deployment) { // nolint:interfacer,gocyclo
+// buildProviderDeployment builds the service account and the deployment of the
+// packaged controller, the client certificate is mounted when it is not nil.
+func buildProviderDeployment(provider *pkgmetav1.Provider, revision v1.PackageRevision, cc *v1.ControllerConfig, namespace string, clientCert *corev1.Secret) (*corev1.ServiceAccount, *appsv1.Deployment) { // nolint:interfacer,gocyclo
 	s := &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            revision.GetName(),
@@ -202,5 +204,28 @@ func buildProviderDeployment(provider *pkgmetav1.Provider, revision v1.PackageRe
 			d.Spec.Template.Spec.Containers[0].Env = cc.Spec.Env
 		}
 	}
+	if clientCert != nil {
+		// the annotations of the controller config are not modified
+		annotations := map[string]string{v1.AnnotationClientCertHash: clientCertHash(clientCert)}
+		for k, v := range d.Spec.Template.Annotations {
+			if _, ok := annotations[k]; !ok {
+				annotations[k] = v
+			}
+		}
+		d.Spec.Template.Annotations = annotations
+		d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: v1.ClientCertVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: clientCert.GetName(),
+				},
+			},
+		})
+		d.Spec.Template.Spec.Containers[0].VolumeMounts = append(d.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      v1.ClientCertVolume,
+			MountPath: v1.ClientCertMountPath,
+			ReadOnly:  true,
+		})
+	}
 	return s, d
 }
@@ -22,6 +22,7 @@ import (
 	pkgmetav1 "github.com/netw-device-driver/ndd-core/apis/pkg/meta/v1"
 	v1 "github.com/netw-device-driver/ndd-core/apis/pkg/v1"
 	"github.com/netw-device-driver/ndd-core/internal/nddpkg"
+	"github.com/netw-device-driver/ndd-core/internal/pki"
 	nddv1 "github.com/netw-device-driver/ndd-runtime/apis/common/v1"
 	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
 	"github.com/pkg/errors"
@@ -66,8 +67,8 @@ func NewProviderHooks(client resource.ClientApplicator, namespace string) *Provi
 	}
 }
 
-// Pre cleans up a packaged controller and service account if the revision is
-// inactive.
+// Pre cleans up a packaged controller, its service account and its client
+// certificate if the revision is inactive.
 func (h *ProviderHooks) Pre(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) error {
 	po, _ := nddpkg.TryConvert(pkg, &pkgmetav1.Provider{})
 	pkgProvider, ok := po.(*pkgmetav1.Provider)
@@ -91,18 +92,22 @@ func (h *ProviderHooks) Pre(ctx context.Context, pkg runtime.Object, pr v1.Packa
 	if err != nil {
 		return errors.Wrap(err, errControllerConfig)
 	}
-	s, d := buildProviderDeployment(pkgProvider, pr, cc, h.namespace)
+	s, d := buildProviderDeployment(pkgProvider, pr, cc, h.namespace, nil)
 	if err := h.client.Delete(ctx, d); resource.IgnoreNotFound(err) != nil {
 		return errors.Wrap(err, errDeleteProviderDeployment)
 	}
 	if err := h.client.Delete(ctx, s); resource.IgnoreNotFound(err) != nil {
 		return errors.Wrap(err, errDeleteProviderSA)
 	}
+	if err := h.client.Delete(ctx, emptyClientCertSecret(pr, h.namespace)); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errDeleteClientCert)
+	}
 	return nil
 }
 
 // Post creates a packaged provider controller and service account if the
-// revision is active.
+// revision is active. The controller is given a client certificate of the
+// certificate authority of the core when the core runs one.
 func (h *ProviderHooks) Post(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) error {
 	po, _ := nddpkg.TryConvert(pkg, &pkgmetav1.Provider{})
 	pkgProvider, ok := po.(*pkgmetav1.Provider)
@@ -116,10 +121,29 @@ func (h *ProviderHooks) Post(ctx context.Context, pkg runtime.Object, pr v1.Pack
 	if err != nil {
 		return errors.Wrap(err, errControllerConfig)
 	}
-	s, d := buildProviderDeployment(pkgProvider, pr, cc, h.namespace)
+	ca, err := pki.LoadCA(ctx, h.client, h.namespace)
+	if err != nil {
+		return err
+	}
+	var cs *corev1.Secret
+	if ca != nil {
+		if cs, err = buildClientCertSecret(ctx, h.client, ca, pr, cc, h.namespace); err != nil {
+			return err
+		}
+	}
+	s, d := buildProviderDeployment(pkgProvider, pr, cc, h.namespace, cs)
 	if err := h.client.Apply(ctx, s); err != nil {
 		return errors.Wrap(err, errApplyProviderSA)
 	}
+	if cs != nil {
+		if err := h.client.Apply(ctx, cs); err != nil {
+			return errors.Wrap(err, errApplyClientCert)
+		}
+	} else {
+		if err := h.client.Delete(ctx, emptyClientCertSecret(pr, h.namespace)); resource.IgnoreNotFound(err) != nil {
+			return errors.Wrap(err, errDeleteClientCert)
+		}
+	}
 	if err := h.client.Apply(ctx, d); err != nil {
 		return errors.Wrap(err, errApplyProviderDeployment)
 	}
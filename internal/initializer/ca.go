@@ -0,0 +1,96 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initializer
+
+import (
+	"context"
+	"time"
+
+	"github.com/netw-device-driver/ndd-core/internal/pki"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	errNoNamespace = "no namespace for the certificate authority"
+	errGetCA       = "cannot get certificate authority"
+	errParseCA     = "cannot parse certificate authority that is not generated by the core, fix or delete secret " + pki.SecretCA
+	errRenewCA     = "certificate authority that is not generated by the core expires, renew secret " + pki.SecretCA
+	errGenerateCA  = "cannot generate certificate authority"
+	errApplyCA     = "cannot apply certificate authority"
+	errPublishCA   = "cannot publish certificate authority"
+)
+
+// NewCertificateAuthority returns a new *CertificateAuthority initializer.
+func NewCertificateAuthority(namespace string, log logging.Logger) *CertificateAuthority {
+	return &CertificateAuthority{namespace: namespace, log: log}
+}
+
+// CertificateAuthority has the initializer for bootstrapping the secret of the
+// certificate authority of the core.
+type CertificateAuthority struct {
+	namespace string
+	log       logging.Logger
+}
+
+// Run makes sure the certificate authority exists and is valid. A certificate
+// authority generated by the core that is invalid or expiring is replaced, the
+// certificates issued by a replaced certificate authority are reissued by the
+// controllers. A certificate authority supplied by the operator is never
+// replaced, an error is returned when it is invalid or expiring. The
+// certificate of the certificate authority is published in a configmap.
+func (ca *CertificateAuthority) Run(ctx context.Context, kube client.Client) error {
+	if ca.namespace == "" {
+		return errors.New(errNoNamespace)
+	}
+	a := resource.NewAPIPatchingApplicator(kube)
+	s := &corev1.Secret{}
+	err := kube.Get(ctx, types.NamespacedName{Namespace: ca.namespace, Name: pki.SecretCA}, s)
+	if resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errGetCA)
+	}
+	var c *pki.CA
+	if err == nil {
+		generated := s.GetAnnotations()[pki.AnnotationGenerated] == "true"
+		c, err = pki.ParseCA(s.Data[corev1.TLSCertKey], s.Data[corev1.TLSPrivateKeyKey])
+		switch {
+		case err != nil && !generated:
+			return errors.Wrap(err, errParseCA)
+		case err == nil && c.NeedsRenewal(time.Now()) && !generated:
+			return errors.New(errRenewCA)
+		case err != nil:
+			ca.log.Info("Replacing invalid certificate authority, the issued certificates are reissued", "secret", pki.SecretCA, "error", err)
+			c = nil
+		case c.NeedsRenewal(time.Now()):
+			ca.log.Info("Replacing expiring certificate authority, the issued certificates are reissued", "secret", pki.SecretCA)
+			c = nil
+		}
+	}
+	if c == nil {
+		if c, err = pki.NewCA(); err != nil {
+			return errors.Wrap(err, errGenerateCA)
+		}
+		if err := a.Apply(ctx, c.Secret(ca.namespace)); err != nil {
+			return errors.Wrap(err, errApplyCA)
+		}
+	}
+	return errors.Wrap(a.Apply(ctx, c.ConfigMap(ca.namespace)), errPublishCA)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initializer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/netw-device-driver/ndd-core/internal/pki"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const namespace = "ndd-system"
+
+func caSecret(generated bool, data map[string][]byte) *corev1.Secret {
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: pki.SecretCA},
+		Type:       corev1.SecretTypeTLS,
+		Data:       data,
+	}
+	if generated {
+		s.SetAnnotations(map[string]string{pki.AnnotationGenerated: "true"})
+	}
+	return s
+}
+
+func TestCertificateAuthority(t *testing.T) {
+	ca, err := pki.NewCA()
+	if err != nil {
+		t.Fatalf("NewCA(): %v", err)
+	}
+	valid := ca.Secret(namespace).Data
+	invalid := map[string][]byte{
+		corev1.TLSCertKey:       []byte("invalid"),
+		corev1.TLSPrivateKeyKey: []byte("invalid"),
+	}
+
+	type want struct {
+		err      bool
+		replaced bool
+	}
+	cases := map[string]struct {
+		reason string
+		secret *corev1.Secret
+		want   want
+	}{
+		"NoCA": {
+			reason: "A certificate authority should be generated when there is none.",
+			want:   want{replaced: true},
+		},
+		"GeneratedValid": {
+			reason: "A valid generated certificate authority should be kept.",
+			secret: caSecret(true, valid),
+			want:   want{replaced: false},
+		},
+		"GeneratedInvalid": {
+			reason: "An invalid generated certificate authority should be replaced.",
+			secret: caSecret(true, invalid),
+			want:   want{replaced: true},
+		},
+		"ForeignValid": {
+			reason: "A valid certificate authority supplied by the operator should be kept.",
+			secret: caSecret(false, valid),
+			want:   want{replaced: false},
+		},
+		"ForeignInvalid": {
+			reason: "A certificate authority supplied by the operator that cannot be parsed should not be replaced.",
+			secret: caSecret(false, invalid),
+			want:   want{err: true, replaced: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(s); err != nil {
+				t.Fatalf("AddToScheme(...): %v", err)
+			}
+			var objs []client.Object
+			if tc.secret != nil {
+				objs = append(objs, tc.secret)
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+
+			err := NewCertificateAuthority(namespace, logging.NewNopLogger()).Run(context.Background(), c)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want error, +got error:\n%s\n%v", tc.reason, diff, err)
+			}
+
+			got := &corev1.Secret{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: pki.SecretCA}, got); err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+			replaced := tc.secret == nil || !cmp.Equal(tc.secret.Data, got.Data)
+			if diff := cmp.Diff(tc.want.replaced, replaced); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want replaced, +got replaced:\n%s", tc.reason, diff)
+			}
+			if replaced && got.GetAnnotations()[pki.AnnotationGenerated] != "true" {
+				t.Errorf("\n%s\nRun(...): the generated certificate authority is not marked as generated", tc.reason)
+			}
+			if tc.want.err {
+				return
+			}
+			cm := &corev1.ConfigMap{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: pki.ConfigMapCA}, cm); err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+			if diff := cmp.Diff(string(got.Data[corev1.TLSCertKey]), cm.Data[pki.KeyCACert]); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want published ca, +got published ca:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
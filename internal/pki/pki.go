@@ -0,0 +1,323 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pki implements the internal certificate authority of the core, which
+// issues the certificates for the mutual TLS between the providers and the
+// device drivers.
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"time"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SecretCA is the name of the secret holding the certificate authority in
+	// the namespace of the core.
+	SecretCA = "ndd-ca"
+
+	// ConfigMapCA is the name of the configmap publishing the certificate of
+	// the certificate authority under the KeyCACert key in the namespace of the
+	// core, such that the certificate can be read without access to the secret.
+	ConfigMapCA = "ndd-ca"
+
+	// AnnotationGenerated marks the secret of a certificate authority that
+	// is generated by the core, only a generated certificate authority is
+	// replaced by the core.
+	AnnotationGenerated = "pki.ndd.yndd.io/generated"
+
+	// KeyCACert is the key of the certificate of the certificate authority in
+	// the certificate secrets, the certificate and the private key are held
+	// under the corev1.TLSCertKey and corev1.TLSPrivateKeyKey keys.
+	KeyCACert = "ca.crt"
+
+	caCommonName = "ndd-ca"
+	caValidity   = 10 * 365 * 24 * time.Hour
+	certValidity = 90 * 24 * time.Hour
+	renewBefore  = 30 * 24 * time.Hour
+
+	pemTypeCertificate = "CERTIFICATE"
+	pemTypePrivateKey  = "EC PRIVATE KEY"
+
+	// Errors
+	errGetCA           = "cannot get certificate authority"
+	errParseCA         = "cannot parse certificate authority"
+	errGenerateKey     = "cannot generate private key"
+	errCreateCert      = "cannot create certificate"
+	errMarshalKey      = "cannot marshal private key"
+	errDecodePEM       = "cannot decode pem block"
+	errParseKey        = "cannot parse private key"
+	errParseCert       = "cannot parse certificate"
+	errGenerateSerial  = "cannot generate serial number"
+	errCANotCertSigner = "certificate authority cannot sign certificates"
+)
+
+// A CA is a certificate authority that issues certificates.
+type CA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// NewCA creates a new self-signed certificate authority.
+func NewCA() (*CA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, errGenerateKey)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: caCommonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateCert)
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCA(encodeCert(der), keyPEM)
+}
+
+// ParseCA parses the pem encoded certificate and private key of a certificate
+// authority.
+func ParseCA(certPEM, keyPEM []byte) (*CA, error) {
+	cert, err := parseCert(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	b, _ := pem.Decode(keyPEM)
+	if b == nil {
+		return nil, errors.New(errDecodePEM)
+	}
+	key, err := x509.ParseECPrivateKey(b.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, errParseKey)
+	}
+	if !cert.IsCA || cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return nil, errors.New(errCANotCertSigner)
+	}
+	return &CA{cert: cert, key: key, certPEM: certPEM, keyPEM: keyPEM}, nil
+}
+
+// LoadCA loads the certificate authority from its secret in the namespace. No
+// certificate authority and no error are returned when the secret does not
+// exist, i.e. when the core runs without a certificate authority.
+func LoadCA(ctx context.Context, c client.Reader, namespace string) (*CA, error) {
+	s := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: SecretCA}, s); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, errGetCA)
+	}
+	ca, err := ParseCA(s.Data[corev1.TLSCertKey], s.Data[corev1.TLSPrivateKeyKey])
+	return ca, errors.Wrap(err, errParseCA)
+}
+
+// Secret returns the secret of the certificate authority in the namespace, the
+// secret is marked as generated by the core.
+func (ca *CA) Secret(namespace string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        SecretCA,
+			Namespace:   namespace,
+			Annotations: map[string]string{AnnotationGenerated: "true"},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       ca.certPEM,
+			corev1.TLSPrivateKeyKey: ca.keyPEM,
+		},
+	}
+}
+
+// ConfigMap returns the configmap publishing the certificate of the certificate
+// authority in the namespace.
+func (ca *CA) ConfigMap(namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ConfigMapCA,
+			Namespace: namespace,
+		},
+		Data: map[string]string{
+			KeyCACert: string(ca.certPEM),
+		},
+	}
+}
+
+// NeedsRenewal returns true if the certificate authority expires within the
+// renewal period of the certificates it issues.
+func (ca *CA) NeedsRenewal(now time.Time) bool {
+	return now.Add(certValidity).After(ca.cert.NotAfter)
+}
+
+// CertPEM returns the pem encoded certificate of the certificate authority.
+func (ca *CA) CertPEM() []byte {
+	return ca.certPEM
+}
+
+// ServiceDNSNames returns the dns names of the services in the namespace.
+func ServiceDNSNames(services []string, namespace string) []string {
+	names := make([]string, 0, 4*len(services))
+	for _, s := range services {
+		names = append(names,
+			s,
+			strings.Join([]string{s, namespace}, "."),
+			strings.Join([]string{s, namespace, "svc"}, "."),
+			ndddvrv1.ServiceDNSName(s, namespace),
+		)
+	}
+	return names
+}
+
+// ServingCertificate returns the data of a serving certificate secret for the
+// dns names. The current data is returned when it holds a certificate of the
+// certificate authority for the dns names that is not due for renewal,
+// otherwise a new certificate is issued.
+func (ca *CA) ServingCertificate(current map[string][]byte, commonName string, dnsNames []string) (map[string][]byte, error) {
+	return ca.certificate(current, commonName, dnsNames, x509.ExtKeyUsageServerAuth)
+}
+
+// ClientCertificate returns the data of a client certificate secret for the
+// common name. The current data is returned when it holds a certificate of the
+// certificate authority for the common name that is not due for renewal,
+// otherwise a new certificate is issued.
+func (ca *CA) ClientCertificate(current map[string][]byte, commonName string) (map[string][]byte, error) {
+	return ca.certificate(current, commonName, nil, x509.ExtKeyUsageClientAuth)
+}
+
+func (ca *CA) certificate(current map[string][]byte, commonName string, dnsNames []string, usage x509.ExtKeyUsage) (map[string][]byte, error) {
+	if ca.valid(current, commonName, dnsNames, time.Now()) {
+		return current, nil
+	}
+	certPEM, keyPEM, err := ca.issue(commonName, dnsNames, usage)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		corev1.TLSCertKey:       certPEM,
+		corev1.TLSPrivateKeyKey: keyPEM,
+		KeyCACert:               ca.certPEM,
+	}, nil
+}
+
+// valid returns true if the data holds a certificate issued by the certificate
+// authority for the common name and dns names that is not due for renewal.
+func (ca *CA) valid(data map[string][]byte, commonName string, dnsNames []string, now time.Time) bool {
+	if !bytes.Equal(data[KeyCACert], ca.certPEM) || len(data[corev1.TLSPrivateKeyKey]) == 0 {
+		return false
+	}
+	cert, err := parseCert(data[corev1.TLSCertKey])
+	if err != nil || cert.CheckSignatureFrom(ca.cert) != nil {
+		return false
+	}
+	if cert.Subject.CommonName != commonName || now.Add(renewBefore).After(cert.NotAfter) {
+		return false
+	}
+	for _, n := range dnsNames {
+		if cert.VerifyHostname(n) != nil {
+			return false
+		}
+	}
+	return len(cert.DNSNames) == len(dnsNames)
+}
+
+// issue issues a certificate signed by the certificate authority.
+func (ca *CA) issue(commonName string, dnsNames []string, usage x509.ExtKeyUsage) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGenerateKey)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, key.Public(), ca.key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errCreateCert)
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeCert(der), keyPEM, nil
+}
+
+func serialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	return serial, errors.Wrap(err, errGenerateSerial)
+}
+
+func encodeCert(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeCertificate, Bytes: der})
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, errMarshalKey)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePrivateKey, Bytes: der}), nil
+}
+
+func parseCert(certPEM []byte) (*x509.Certificate, error) {
+	b, _ := pem.Decode(certPEM)
+	if b == nil {
+		return nil, errors.New(errDecodePEM)
+	}
+	cert, err := x509.ParseCertificate(b.Bytes)
+	return cert, errors.Wrap(err, errParseCert)
+}
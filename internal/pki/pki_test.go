@@ -0,0 +1,304 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// rsaCA returns the pem encoded certificate and private key of a certificate
+// authority with an RSA key, as supplied by an operator.
+func rsaCA(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey(...): %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "operator-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("CreateCertificate(...): %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return encodeCert(der), keyPEM
+}
+
+func newCA(t *testing.T) *CA {
+	t.Helper()
+	ca, err := NewCA()
+	if err != nil {
+		t.Fatalf("NewCA(): %v", err)
+	}
+	return ca
+}
+
+func TestParseCA(t *testing.T) {
+	ca := newCA(t)
+	rsaCert, rsaKey := rsaCA(t)
+	other := newCA(t)
+	leaf, _, err := ca.issue("leaf", nil, x509.ExtKeyUsageServerAuth)
+	if err != nil {
+		t.Fatalf("issue(...): %v", err)
+	}
+
+	cases := map[string]struct {
+		reason  string
+		certPEM []byte
+		keyPEM  []byte
+		wantErr bool
+	}{
+		"Generated": {
+			reason:  "A generated certificate authority should be parsed.",
+			certPEM: ca.certPEM,
+			keyPEM:  ca.keyPEM,
+		},
+		"RSA": {
+			reason:  "A certificate authority with an RSA key should not be parsed.",
+			certPEM: rsaCert,
+			keyPEM:  rsaKey,
+			wantErr: true,
+		},
+		"NotCA": {
+			reason:  "A certificate that is not a certificate authority should not be parsed.",
+			certPEM: leaf,
+			keyPEM:  ca.keyPEM,
+			wantErr: true,
+		},
+		"NoKey": {
+			reason:  "A certificate authority without private key should not be parsed.",
+			certPEM: other.certPEM,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseCA(tc.certPEM, tc.keyPEM)
+			if diff := cmp.Diff(tc.wantErr, err != nil); diff != "" {
+				t.Errorf("\n%s\nParseCA(...): -want error, +got error:\n%s\n%v", tc.reason, diff, err)
+			}
+		})
+	}
+}
+
+func TestNeedsRenewal(t *testing.T) {
+	ca := newCA(t)
+
+	cases := map[string]struct {
+		reason string
+		now    time.Time
+		want   bool
+	}{
+		"Valid": {
+			reason: "A new certificate authority should not need renewal.",
+			now:    time.Now(),
+			want:   false,
+		},
+		"Expiring": {
+			reason: "A certificate authority that expires before the certificates it issues should need renewal.",
+			now:    ca.cert.NotAfter.Add(-certValidity + time.Hour),
+			want:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, ca.NeedsRenewal(tc.now)); diff != "" {
+				t.Errorf("\n%s\nNeedsRenewal(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestServingCertificate(t *testing.T) {
+	ca := newCA(t)
+	dnsNames := ServiceDNSNames([]string{"ndd-svc-leaf1"}, "ndd-system")
+	current, err := ca.ServingCertificate(nil, "ndd-svc-leaf1", dnsNames)
+	if err != nil {
+		t.Fatalf("ServingCertificate(...): %v", err)
+	}
+
+	cases := map[string]struct {
+		reason     string
+		ca         *CA
+		current    map[string][]byte
+		commonName string
+		dnsNames   []string
+		reissued   bool
+	}{
+		"Valid": {
+			reason:     "A valid certificate should be kept.",
+			ca:         ca,
+			current:    current,
+			commonName: "ndd-svc-leaf1",
+			dnsNames:   dnsNames,
+			reissued:   false,
+		},
+		"Missing": {
+			reason:     "A certificate should be issued when there is none.",
+			ca:         ca,
+			commonName: "ndd-svc-leaf1",
+			dnsNames:   dnsNames,
+			reissued:   true,
+		},
+		"CommonNameMismatch": {
+			reason:     "A certificate for another common name should be reissued.",
+			ca:         ca,
+			current:    current,
+			commonName: "ndd-svc-leaf2",
+			dnsNames:   dnsNames,
+			reissued:   true,
+		},
+		"DNSNameMismatch": {
+			reason:     "A certificate for other dns names should be reissued.",
+			ca:         ca,
+			current:    current,
+			commonName: "ndd-svc-leaf1",
+			dnsNames:   ServiceDNSNames([]string{"ndd-svc-leaf1"}, "other"),
+			reissued:   true,
+		},
+		"DNSNameAdded": {
+			reason:     "A certificate that lacks a dns name should be reissued.",
+			ca:         ca,
+			current:    current,
+			commonName: "ndd-svc-leaf1",
+			dnsNames:   append(append([]string{}, dnsNames...), "leaf1.example.com"),
+			reissued:   true,
+		},
+		"DNSNameRemoved": {
+			reason:     "A certificate with a dns name that is no longer needed should be reissued.",
+			ca:         ca,
+			current:    current,
+			commonName: "ndd-svc-leaf1",
+			dnsNames:   dnsNames[:1],
+			reissued:   true,
+		},
+		"CARotated": {
+			reason:     "A certificate of a replaced certificate authority should be reissued.",
+			ca:         newCA(t),
+			current:    current,
+			commonName: "ndd-svc-leaf1",
+			dnsNames:   dnsNames,
+			reissued:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.ca.ServingCertificate(tc.current, tc.commonName, tc.dnsNames)
+			if err != nil {
+				t.Fatalf("\n%s\nServingCertificate(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.reissued, !cmp.Equal(tc.current, got)); diff != "" {
+				t.Errorf("\n%s\nServingCertificate(...): -want reissued, +got reissued:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.ca.certPEM, got[KeyCACert]); diff != "" {
+				t.Errorf("\n%s\nServingCertificate(...): -want ca, +got ca:\n%s", tc.reason, diff)
+			}
+			if !tc.ca.valid(got, tc.commonName, tc.dnsNames, time.Now()) {
+				t.Errorf("\n%s\nServingCertificate(...): the certificate is not valid", tc.reason)
+			}
+			cert, err := parseCert(got[corev1.TLSCertKey])
+			if err != nil {
+				t.Fatalf("parseCert(...): %v", err)
+			}
+			if diff := cmp.Diff([]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, cert.ExtKeyUsage); diff != "" {
+				t.Errorf("\n%s\nServingCertificate(...): -want usage, +got usage:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	ca := newCA(t)
+	current, err := ca.ClientCertificate(nil, "provider")
+	if err != nil {
+		t.Fatalf("ClientCertificate(...): %v", err)
+	}
+	cert, err := parseCert(current[corev1.TLSCertKey])
+	if err != nil {
+		t.Fatalf("parseCert(...): %v", err)
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   map[string][]byte
+		now    time.Time
+		want   bool
+	}{
+		"Valid": {
+			reason: "A certificate that is not due for renewal should be valid.",
+			data:   current,
+			now:    time.Now(),
+			want:   true,
+		},
+		"Renewal": {
+			reason: "A certificate that expires within the renewal period should be renewed.",
+			data:   current,
+			now:    cert.NotAfter.Add(-renewBefore + time.Hour),
+			want:   false,
+		},
+		"NoKey": {
+			reason: "A certificate without private key should be reissued.",
+			data: map[string][]byte{
+				corev1.TLSCertKey: current[corev1.TLSCertKey],
+				KeyCACert:         current[KeyCACert],
+			},
+			now:  time.Now(),
+			want: false,
+		},
+		"ForeignCertificate": {
+			reason: "A certificate that is not signed by the certificate authority should be reissued.",
+			data: func() map[string][]byte {
+				other, err := newCA(t).ClientCertificate(nil, "provider")
+				if err != nil {
+					t.Fatalf("ClientCertificate(...): %v", err)
+				}
+				other[KeyCACert] = ca.certPEM
+				return other
+			}(),
+			now:  time.Now(),
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, ca.valid(tc.data, "provider", nil, tc.now)); diff != "" {
+				t.Errorf("\n%s\nvalid(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
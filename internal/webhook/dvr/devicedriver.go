@@ -0,0 +1,137 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dvr
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/controllers/dvr/nn"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// the webhook path of the device drivers, the device drivers are not
+	// defaulted such that a device driver without device driver kind label is
+	// only used by the network nodes referencing it
+	pathValidateDeviceDriver = "/validate-dvr-ndd-yndd-io-v1-devicedriver"
+)
+
+// +kubebuilder:webhook:path=/validate-dvr-ndd-yndd-io-v1-devicedriver,mutating=false,failurePolicy=fail,sideEffects=None,groups=dvr.ndd.yndd.io,resources=devicedrivers,verbs=create;update,versions=v1,name=vdevicedriver.dvr.ndd.yndd.io,admissionReviewVersions=v1
+
+// A DeviceDriverValidator validates the device drivers.
+type DeviceDriverValidator struct {
+	kinds   nn.KindRegistry
+	log     logging.Logger
+	decoder *admission.Decoder
+}
+
+// NewDeviceDriverValidator creates a new DeviceDriverValidator.
+func NewDeviceDriverValidator(kinds nn.KindRegistry, log logging.Logger, decoder *admission.Decoder) *DeviceDriverValidator {
+	return &DeviceDriverValidator{
+		kinds:   kinds,
+		log:     log,
+		decoder: decoder,
+	}
+}
+
+// Handle validates the device driver of the admission request, the device
+// driver is denied with the list of its invalid fields.
+func (v *DeviceDriverValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	dd := &ndddvrv1.DeviceDriver{}
+	if err := v.decoder.Decode(req, dd); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	errs, err := v.validate(ctx, dd)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(req.OldObject.Raw) > 0 {
+		old := &ndddvrv1.DeviceDriver{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		errs = append(errs, validateDeviceDriverUpdate(old, dd)...)
+	}
+	if len(errs) > 0 {
+		v.log.Debug("Deny device driver", "namespace", dd.GetNamespace(), "name", dd.GetName(), "error", errs.ToAggregate())
+		return admission.Denied(errs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+// validate validates the device driver kind and the grpc server port of the
+// shards of the device driver, the device driver kind must be registered in the
+// kind registry of the device driver controllers.
+func (v *DeviceDriverValidator) validate(ctx context.Context, dd *ndddvrv1.DeviceDriver) (field.ErrorList, error) {
+	var errs field.ErrorList
+	if kind, ok := dd.GetLabels()[ndddvrv1.LabelDeviceDriverKind]; ok {
+		kindErrs, err := validateKind(ctx, v.kinds, field.NewPath("metadata", "labels").Key(ndddvrv1.LabelDeviceDriverKind), ndddvrv1.DeviceDriverKind(kind))
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, kindErrs...)
+	}
+	if s := dd.Spec.Sharding; s != nil && s.GrpcServerPort != nil {
+		path := field.NewPath("spec", "sharding", "grpcServerPort")
+		for _, msg := range validation.IsValidPortNum(*s.GrpcServerPort) {
+			errs = append(errs, field.Invalid(path, *s.GrpcServerPort, msg))
+		}
+		for _, c := range sidecarPortClashes(dd, *s.GrpcServerPort) {
+			errs = append(errs, field.Duplicate(path, c))
+		}
+	}
+	return errs, nil
+}
+
+// validateDeviceDriverUpdate validates the fields of the device driver that
+// cannot be changed.
+func validateDeviceDriverUpdate(old, dd *ndddvrv1.DeviceDriver) field.ErrorList {
+	var errs field.ErrorList
+	if old.GetLabels()[ndddvrv1.LabelDeviceDriverKind] != dd.GetLabels()[ndddvrv1.LabelDeviceDriverKind] {
+		errs = append(errs, field.Forbidden(field.NewPath("metadata", "labels").Key(ndddvrv1.LabelDeviceDriverKind),
+			"the device driver kind cannot be changed, delete and recreate the device driver"))
+	}
+	return errs
+}
+
+// sidecarPortClashes returns the sidecar containers of the device driver that
+// expose the port, the device driver container is the container of the device
+// driver when specified, otherwise the first container of the pod template.
+func sidecarPortClashes(dd *ndddvrv1.DeviceDriver, port int) []string {
+	if dd.Spec.PodTemplate == nil {
+		return nil
+	}
+	sidecars := dd.Spec.PodTemplate.Spec.Containers
+	if dd.Spec.Container == nil && len(sidecars) > 0 {
+		sidecars = sidecars[1:]
+	}
+	var clashes []string
+	for _, c := range sidecars {
+		for _, p := range c.Ports {
+			if int(p.ContainerPort) == port {
+				clashes = append(clashes, "port "+strconv.Itoa(port)+" of container "+c.Name)
+			}
+		}
+	}
+	return clashes
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dvr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/controllers/dvr/nn"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type deviceDriverModifier func(dd *ndddvrv1.DeviceDriver)
+
+func withKindLabel(k ndddvrv1.DeviceDriverKind) deviceDriverModifier {
+	return func(dd *ndddvrv1.DeviceDriver) {
+		dd.SetLabels(map[string]string{ndddvrv1.LabelDeviceDriverKind: string(k)})
+	}
+}
+
+func withShardPort(p int) deviceDriverModifier {
+	return func(dd *ndddvrv1.DeviceDriver) {
+		dd.Spec.Sharding = &ndddvrv1.ShardingSpec{GrpcServerPort: &p}
+	}
+}
+
+func withSidecarPort(p int32) deviceDriverModifier {
+	return func(dd *ndddvrv1.DeviceDriver) {
+		dd.Spec.PodTemplate = &corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "nddriver"},
+					{Name: "sidecar", Ports: []corev1.ContainerPort{{ContainerPort: p}}},
+				},
+			},
+		}
+	}
+}
+
+func deviceDriver(m ...deviceDriverModifier) *ndddvrv1.DeviceDriver {
+	dd := &ndddvrv1.DeviceDriver{ObjectMeta: metav1.ObjectMeta{Namespace: ndddvrv1.DefaultNamespace, Name: "dd"}}
+	for _, f := range m {
+		f(dd)
+	}
+	return dd
+}
+
+func TestDeviceDriverValidatorValidate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		dd     *ndddvrv1.DeviceDriver
+		want   []string
+	}{
+		"Unlabeled": {
+			reason: "A device driver without device driver kind label should be allowed.",
+			dd:     deviceDriver(),
+		},
+		"BuiltInKind": {
+			reason: "A device driver of a built-in device driver kind should be allowed.",
+			dd:     deviceDriver(withKindLabel(ndddvrv1.DeviceDriverKindNetconf)),
+		},
+		"RegisteredKind": {
+			reason: "A device driver of a device driver kind of the kinds configmap should be allowed.",
+			dd:     deviceDriver(withKindLabel(customKind)),
+		},
+		"UnknownKind": {
+			reason: "A device driver of an unregistered device driver kind should be denied.",
+			dd:     deviceDriver(withKindLabel("unknown")),
+			want:   []string{"Invalid value metadata.labels[" + ndddvrv1.LabelDeviceDriverKind + "]"},
+		},
+		"InvalidShardPort": {
+			reason: "A sharded device driver with an invalid grpc server port should be denied.",
+			dd:     deviceDriver(withShardPort(0)),
+			want:   []string{"Invalid value spec.sharding.grpcServerPort"},
+		},
+		"ShardPortClash": {
+			reason: "A sharded device driver of which a sidecar exposes the grpc server port should be denied.",
+			dd:     deviceDriver(withShardPort(9999), withSidecarPort(9999)),
+			want:   []string{"Duplicate value spec.sharding.grpcServerPort"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := newFakeClient(t)
+			v := &DeviceDriverValidator{kinds: nn.NewConfigMapKindRegistry(c, ndddvrv1.Namespace), log: logging.NewNopLogger()}
+			errs, err := v.validate(context.Background(), tc.dd)
+			if err != nil {
+				t.Fatalf("\n%s\nvalidate(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, errorFields(errs)); diff != "" {
+				t.Errorf("\n%s\nvalidate(...): -want errors, +got errors:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateDeviceDriverUpdate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		old    *ndddvrv1.DeviceDriver
+		dd     *ndddvrv1.DeviceDriver
+		want   []string
+	}{
+		"Unchanged": {
+			reason: "A device driver of which the device driver kind is unchanged should be allowed.",
+			old:    deviceDriver(withKindLabel(ndddvrv1.DeviceDriverKindGnmi)),
+			dd:     deviceDriver(withKindLabel(ndddvrv1.DeviceDriverKindGnmi), withShardPort(9999)),
+		},
+		"Changed": {
+			reason: "A device driver of which the device driver kind changes should be denied.",
+			old:    deviceDriver(withKindLabel(ndddvrv1.DeviceDriverKindGnmi)),
+			dd:     deviceDriver(withKindLabel(ndddvrv1.DeviceDriverKindNetconf)),
+			want:   []string{"Forbidden metadata.labels[" + ndddvrv1.LabelDeviceDriverKind + "]"},
+		},
+		"Labeled": {
+			reason: "A device driver that is labeled with a device driver kind should be denied.",
+			old:    deviceDriver(),
+			dd:     deviceDriver(withKindLabel(ndddvrv1.DeviceDriverKindGnmi)),
+			want:   []string{"Forbidden metadata.labels[" + ndddvrv1.LabelDeviceDriverKind + "]"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			errs := validateDeviceDriverUpdate(tc.old, tc.dd)
+			if diff := cmp.Diff(tc.want, errorFields(errs)); diff != "" {
+				t.Errorf("\n%s\nvalidateDeviceDriverUpdate(...): -want errors, +got errors:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
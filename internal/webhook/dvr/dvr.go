@@ -0,0 +1,41 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dvr
+
+import (
+	"github.com/netw-device-driver/ndd-core/internal/controllers/dvr/nn"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Setup registers the webhooks of the network nodes and the device drivers
+// with the webhook server of the supplied manager. The device driver kinds are
+// validated against the kinds configmap in the namespace of the core.
+func Setup(mgr ctrl.Manager, l logging.Logger, namespace string) error {
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+	kinds := nn.NewConfigMapKindRegistry(mgr.GetAPIReader(), namespace)
+	s := mgr.GetWebhookServer()
+	s.Register(pathMutateNetworkNode, &webhook.Admission{Handler: NewNetworkNodeDefaulter(l.WithValues("webhook", "networknode"), decoder)})
+	s.Register(pathValidateNetworkNode, &webhook.Admission{Handler: NewNetworkNodeValidator(mgr.GetAPIReader(), kinds, l.WithValues("webhook", "networknode"), decoder)})
+	s.Register(pathValidateDeviceDriver, &webhook.Admission{Handler: NewDeviceDriverValidator(kinds, l.WithValues("webhook", "devicedriver"), decoder)})
+	return nil
+}
@@ -0,0 +1,380 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dvr
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/controllers/dvr/nn"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/meta"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// the webhook paths of the network nodes
+	pathMutateNetworkNode   = "/mutate-dvr-ndd-yndd-io-v1-networknode"
+	pathValidateNetworkNode = "/validate-dvr-ndd-yndd-io-v1-networknode"
+)
+
+// encodings are the encodings the built-in device driver kinds accept, netconf
+// device drivers encode in xml and only accept the default encoding. The
+// encodings of the device driver kinds registered in the kinds configmap are
+// not validated.
+var encodings = map[ndddvrv1.DeviceDriverKind][]string{
+	ndddvrv1.DeviceDriverKindGnmi:    {"JSON", "BYTES", "PROTO", "ASCII", "JSON_IETF"},
+	ndddvrv1.DeviceDriverKindNetconf: {"JSON_IETF"},
+}
+
+// +kubebuilder:webhook:path=/mutate-dvr-ndd-yndd-io-v1-networknode,mutating=true,failurePolicy=fail,sideEffects=None,groups=dvr.ndd.yndd.io,resources=networknodes,verbs=create;update,versions=v1,name=mnetworknode.dvr.ndd.yndd.io,admissionReviewVersions=v1
+
+// A NetworkNodeDefaulter defaults the network nodes.
+type NetworkNodeDefaulter struct {
+	log     logging.Logger
+	decoder *admission.Decoder
+}
+
+// NewNetworkNodeDefaulter creates a new NetworkNodeDefaulter.
+func NewNetworkNodeDefaulter(log logging.Logger, decoder *admission.Decoder) *NetworkNodeDefaulter {
+	return &NetworkNodeDefaulter{
+		log:     log,
+		decoder: decoder,
+	}
+}
+
+// Handle defaults the network node of the admission request. A network node
+// without network node class gets the built-in defaults in its spec, the
+// network node is labeled with its device driver kind and the device driver
+// reference defaults to the default namespace.
+func (d *NetworkNodeDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	nn := &ndddvrv1.NetworkNode{}
+	if err := d.decoder.Decode(req, nn); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	defaultNetworkNode(nn)
+	b, err := json.Marshal(nn)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	d.log.Debug("Default network node", "name", nn.GetName())
+	return admission.PatchResponseFromRaw(req.Object.Raw, b)
+}
+
+// defaultNetworkNode defaults the network node.
+func defaultNetworkNode(nn *ndddvrv1.NetworkNode) {
+	if nn.GetNetworkNodeClassName() == "" && nn.Spec.Target != nil {
+		// the fields of a network node with network node class default to
+		// the class, which is resolved by the network node controller
+		nn.Spec = *ndddvrv1.EffectiveNetworkNodeSpec(&nn.Spec, nil)
+	}
+	if nn.Spec.DeviceDriverKind != nil {
+		meta.AddLabels(nn, map[string]string{ndddvrv1.LabelDeviceDriverKind: string(*nn.Spec.DeviceDriverKind)})
+	}
+	if r := nn.Spec.DeviceDriverReference; r != nil && r.Namespace == "" {
+		r.Namespace = ndddvrv1.DefaultNamespace
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-dvr-ndd-yndd-io-v1-networknode,mutating=false,failurePolicy=fail,sideEffects=None,groups=dvr.ndd.yndd.io,resources=networknodes,verbs=create;update,versions=v1,name=vnetworknode.dvr.ndd.yndd.io,admissionReviewVersions=v1
+
+// A NetworkNodeValidator validates the network nodes.
+type NetworkNodeValidator struct {
+	client  client.Reader
+	kinds   nn.KindRegistry
+	log     logging.Logger
+	decoder *admission.Decoder
+}
+
+// NewNetworkNodeValidator creates a new NetworkNodeValidator.
+func NewNetworkNodeValidator(c client.Reader, kinds nn.KindRegistry, log logging.Logger, decoder *admission.Decoder) *NetworkNodeValidator {
+	return &NetworkNodeValidator{
+		client:  c,
+		kinds:   kinds,
+		log:     log,
+		decoder: decoder,
+	}
+}
+
+// Handle validates the network node of the admission request, the network node
+// is denied with the list of its invalid fields.
+func (v *NetworkNodeValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	nn := &ndddvrv1.NetworkNode{}
+	if err := v.decoder.Decode(req, nn); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	errs, err := v.validate(ctx, nn)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(req.OldObject.Raw) > 0 {
+		old := &ndddvrv1.NetworkNode{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		updateErrs, err := v.validateUpdate(ctx, old, nn)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		errs = append(errs, updateErrs...)
+	}
+	if len(errs) > 0 {
+		v.log.Debug("Deny network node", "name", nn.GetName(), "error", errs.ToAggregate())
+		return admission.Denied(errs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+// validate validates the target, the device driver kind, the encoding and the
+// grpc server port of the network node and checks the referenced network node
+// class and secrets exist. The device driver kind must be registered in the
+// kind registry of the device driver controllers. The network node is validated with the defaults of
+// its network node class.
+func (v *NetworkNodeValidator) validate(ctx context.Context, nn *ndddvrv1.NetworkNode) (field.ErrorList, error) {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	class, err := v.class(ctx, nn)
+	if kerrors.IsNotFound(err) {
+		return append(errs, field.NotFound(spec.Child("networkNodeClassName"), *nn.Spec.NetworkNodeClassName)), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if nn.Spec.Target == nil {
+		return append(errs, field.Required(spec.Child("target"), "")), nil
+	}
+	effective := ndddvrv1.EffectiveNetworkNodeSpec(&nn.Spec, class)
+
+	kind := *effective.DeviceDriverKind
+	kindErrs, err := validateKind(ctx, v.kinds, spec.Child("deviceDriverKind"), kind)
+	if err != nil {
+		return nil, err
+	}
+	errs = append(errs, kindErrs...)
+
+	port := *effective.GrpcServerPort
+	for _, msg := range validation.IsValidPortNum(port) {
+		errs = append(errs, field.Invalid(spec.Child("grpcServerPort"), port, msg))
+	}
+
+	t := effective.Target
+	target := spec.Child("target")
+	if t.Address == nil || *t.Address == "" {
+		errs = append(errs, field.Required(target.Child("address"), ""))
+	} else {
+		errs = append(errs, validateAddress(target.Child("address"), *t.Address)...)
+	}
+	if t.Proxy != nil && *t.Proxy != "" {
+		errs = append(errs, validateProxy(target.Child("proxy"), *t.Proxy)...)
+	} else if t.ProxyCredentialsName != nil && *t.ProxyCredentialsName != "" {
+		errs = append(errs, field.Invalid(target.Child("proxyCredentialsName"), *t.ProxyCredentialsName, "proxy credentials require a proxy"))
+	}
+	if t.Encoding != nil {
+		if supported, ok := encodings[kind]; ok && !contains(supported, *t.Encoding) {
+			errs = append(errs, field.NotSupported(target.Child("encoding"), *t.Encoding, supported))
+		}
+	}
+
+	secrets := []struct {
+		path *field.Path
+		name *string
+	}{
+		{path: target.Child("credentialsName"), name: t.CredentialsName},
+		{path: target.Child("tlsCredentialsName"), name: t.TLSCredentialsName},
+		{path: target.Child("proxyCredentialsName"), name: t.ProxyCredentialsName},
+	}
+	if t.CredentialsName == nil || *t.CredentialsName == "" {
+		errs = append(errs, field.Required(target.Child("credentialsName"), "must be specified by the network node or its network node class"))
+	}
+	for _, s := range secrets {
+		if s.name == nil || *s.name == "" {
+			continue
+		}
+		err := v.client.Get(ctx, types.NamespacedName{Namespace: ndddvrv1.DefaultNamespace, Name: *s.name}, &corev1.Secret{})
+		if kerrors.IsNotFound(err) {
+			errs = append(errs, field.NotFound(s.path, ndddvrv1.DefaultNamespace+"/"+*s.name))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	clashes, err := v.portClashes(ctx, effective, port)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range clashes {
+		errs = append(errs, field.Duplicate(spec.Child("grpcServerPort"), c))
+	}
+	return errs, nil
+}
+
+// portClashes returns the containers of the referenced device driver of which
+// the ports clash with the grpc server port of the network node. The grpc
+// server of a sharded device driver listens on the port of the shards.
+func (v *NetworkNodeValidator) portClashes(ctx context.Context, spec *ndddvrv1.NetworkNodeSpec, port int) ([]string, error) {
+	r := spec.DeviceDriverReference
+	if r == nil {
+		return nil, nil
+	}
+	namespace := r.Namespace
+	if namespace == "" {
+		namespace = ndddvrv1.DefaultNamespace
+	}
+	dd := &ndddvrv1.DeviceDriver{}
+	err := v.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: r.Name}, dd)
+	if kerrors.IsNotFound(err) {
+		// the network node waits for the device driver
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dd.Spec.Sharding != nil {
+		return nil, nil
+	}
+	return sidecarPortClashes(dd, port), nil
+}
+
+// class returns the network node class of the network node, or nil when the
+// network node has no network node class.
+func (v *NetworkNodeValidator) class(ctx context.Context, nn *ndddvrv1.NetworkNode) (*ndddvrv1.NetworkNodeClassSpec, error) {
+	name := nn.Spec.NetworkNodeClassName
+	if name == nil || *name == "" {
+		return nil, nil
+	}
+	c := &ndddvrv1.NetworkNodeClass{}
+	if err := v.client.Get(ctx, types.NamespacedName{Name: *name}, c); err != nil {
+		return nil, err
+	}
+	return &c.Spec, nil
+}
+
+// validateUpdate validates the fields of the network node that cannot be
+// changed. The effective device driver kinds are compared, i.e. the device
+// driver kinds of the network nodes with the defaults of their network node
+// classes, such that the kind cannot be changed through the network node
+// class or by unsetting it. The kind of a network node whose network node
+// class does not exist cannot be resolved and is not compared.
+func (v *NetworkNodeValidator) validateUpdate(ctx context.Context, old, nn *ndddvrv1.NetworkNode) (field.ErrorList, error) {
+	oldClass, err := v.class(ctx, old)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	class, err := v.class(ctx, nn)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var errs field.ErrorList
+	oldKind := *ndddvrv1.EffectiveNetworkNodeSpec(&old.Spec, oldClass).DeviceDriverKind
+	kind := *ndddvrv1.EffectiveNetworkNodeSpec(&nn.Spec, class).DeviceDriverKind
+	if oldKind != kind {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "deviceDriverKind"),
+			"the device driver kind cannot be changed from "+string(oldKind)+" to "+string(kind)+", delete and recreate the network node"))
+	}
+	return errs, nil
+}
+
+// validateAddress validates the address is a host and a port, a host that is
+// an IPv6 literal is enclosed in square brackets.
+func validateAddress(path *field.Path, address string) field.ErrorList {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, address, "must be host:port, IPv6 literals as [host]:port: "+err.Error())}
+	}
+	var errs field.ErrorList
+	errs = append(errs, validateHost(path, address, host)...)
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return append(errs, field.Invalid(path, address, "port must be a number"))
+	}
+	for _, msg := range validation.IsValidPortNum(p) {
+		errs = append(errs, field.Invalid(path, address, msg))
+	}
+	return errs
+}
+
+// validateHost validates the host is an IP address or a DNS name.
+func validateHost(path *field.Path, address, host string) field.ErrorList {
+	if host == "" {
+		return field.ErrorList{field.Invalid(path, address, "host must not be empty")}
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(host) {
+		errs = append(errs, field.Invalid(path, address, "host must be an IP address or a DNS name: "+msg))
+	}
+	return errs
+}
+
+// validateProxy validates the proxy is a URL with a supported scheme, a host
+// and a port.
+func validateProxy(path *field.Path, proxy string) field.ErrorList {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, proxy, err.Error())}
+	}
+	switch u.Scheme {
+	case "socks5", "socks5h", "http":
+	default:
+		return field.ErrorList{field.NotSupported(path, u.Scheme, []string{"socks5", "socks5h", "http"})}
+	}
+	if u.User != nil {
+		return field.ErrorList{field.Invalid(path, proxy, "credentials must be specified in the proxy credentials secret")}
+	}
+	return validateAddress(path, u.Host)
+}
+
+// validateKind validates the device driver kind is registered in the kind
+// registry, i.e. it is a built-in kind or a kind of the kinds configmap.
+func validateKind(ctx context.Context, kinds nn.KindRegistry, path *field.Path, kind ndddvrv1.DeviceDriverKind) (field.ErrorList, error) {
+	_, err := kinds.Get(ctx, kind)
+	if nn.IsUnknownKind(err) {
+		return field.ErrorList{field.Invalid(path, kind, "unknown device driver kind, register the kind in the "+nn.KindsConfigMap+" configmap")}, nil
+	}
+	return nil, err
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
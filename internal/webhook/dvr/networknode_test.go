@@ -0,0 +1,343 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dvr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ndddvrv1 "github.com/netw-device-driver/ndd-core/apis/dvr/v1"
+	"github.com/netw-device-driver/ndd-core/internal/controllers/dvr/nn"
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// the device driver kind registered in the kinds configmap
+const customKind ndddvrv1.DeviceDriverKind = "custom"
+
+// newFakeClient returns a fake client holding the objects, the credentials
+// secret and the kinds configmap registering the custom device driver kind.
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme(...): %v", err)
+	}
+	if err := ndddvrv1.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme(...): %v", err)
+	}
+	objs = append(objs,
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: ndddvrv1.DefaultNamespace, Name: "creds"}},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ndddvrv1.Namespace, Name: nn.KindsConfigMap},
+			Data:       map[string]string{string(customKind): "image: example/custom:v0.1.0"},
+		},
+	)
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+// errorFields returns the type and the field of the errors, which identify the
+// errors without their messages.
+func errorFields(errs field.ErrorList) []string {
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Type.String()+" "+e.Field)
+	}
+	return fields
+}
+
+type networkNodeModifier func(nn *ndddvrv1.NetworkNode)
+
+func withKind(k ndddvrv1.DeviceDriverKind) networkNodeModifier {
+	return func(nn *ndddvrv1.NetworkNode) { nn.Spec.DeviceDriverKind = &k }
+}
+
+func withAddress(a string) networkNodeModifier {
+	return func(nn *ndddvrv1.NetworkNode) { nn.Spec.Target.Address = &a }
+}
+
+func withEncoding(e string) networkNodeModifier {
+	return func(nn *ndddvrv1.NetworkNode) { nn.Spec.Target.Encoding = &e }
+}
+
+func withCredentials(c *string) networkNodeModifier {
+	return func(nn *ndddvrv1.NetworkNode) { nn.Spec.Target.CredentialsName = c }
+}
+
+func withProxy(p string) networkNodeModifier {
+	return func(nn *ndddvrv1.NetworkNode) { nn.Spec.Target.Proxy = &p }
+}
+
+func withProxyCredentials(c string) networkNodeModifier {
+	return func(nn *ndddvrv1.NetworkNode) { nn.Spec.Target.ProxyCredentialsName = &c }
+}
+
+func withClass(c string) networkNodeModifier {
+	return func(nn *ndddvrv1.NetworkNode) { nn.Spec.NetworkNodeClassName = &c }
+}
+
+func networkNode(m ...networkNodeModifier) *ndddvrv1.NetworkNode {
+	nn := &ndddvrv1.NetworkNode{
+		ObjectMeta: metav1.ObjectMeta{Name: "leaf1"},
+		Spec: ndddvrv1.NetworkNodeSpec{
+			Target: &ndddvrv1.TargetDetails{
+				Address:         utils.StringPtr("10.0.0.1:57400"),
+				CredentialsName: utils.StringPtr("creds"),
+			},
+		},
+	}
+	for _, f := range m {
+		f(nn)
+	}
+	return nn
+}
+
+func networkNodeClass(name string, kind ndddvrv1.DeviceDriverKind) *ndddvrv1.NetworkNodeClass {
+	return &ndddvrv1.NetworkNodeClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       ndddvrv1.NetworkNodeClassSpec{DeviceDriverKind: &kind},
+	}
+}
+
+func TestNetworkNodeDefaulter(t *testing.T) {
+	gnmi := ndddvrv1.DeviceDriverKindGnmi
+	netconf := ndddvrv1.DeviceDriverKindNetconf
+	port := ndddvrv1.DefaultGrpcServerPort
+	encoding := ndddvrv1.DefaultEncoding
+
+	cases := map[string]struct {
+		reason string
+		nn     *ndddvrv1.NetworkNode
+		want   *ndddvrv1.NetworkNode
+	}{
+		"NoClass": {
+			reason: "A network node without network node class should get the built-in defaults.",
+			nn:     networkNode(),
+			want: func() *ndddvrv1.NetworkNode {
+				nn := networkNode(withKind(gnmi), withEncoding(encoding))
+				nn.SetLabels(map[string]string{ndddvrv1.LabelDeviceDriverKind: string(gnmi)})
+				nn.Spec.GrpcServerPort = &port
+				nn.Spec.Target.SkipVerify = utils.BoolPtr(false)
+				nn.Spec.Target.Insecure = utils.BoolPtr(false)
+				return nn
+			}(),
+		},
+		"Class": {
+			reason: "A network node with network node class should keep its unset fields, which default to the class.",
+			nn:     networkNode(withClass("spine")),
+			want:   networkNode(withClass("spine")),
+		},
+		"ClassWithKind": {
+			reason: "A network node with network node class should be labeled with the device driver kind it specifies.",
+			nn:     networkNode(withClass("spine"), withKind(netconf)),
+			want: func() *ndddvrv1.NetworkNode {
+				nn := networkNode(withClass("spine"), withKind(netconf))
+				nn.SetLabels(map[string]string{ndddvrv1.LabelDeviceDriverKind: string(netconf)})
+				return nn
+			}(),
+		},
+		"DeviceDriverReference": {
+			reason: "The device driver reference of a network node should default to the default namespace.",
+			nn: func() *ndddvrv1.NetworkNode {
+				nn := networkNode(withClass("spine"))
+				nn.Spec.DeviceDriverReference = &ndddvrv1.DeviceDriverReference{Name: "gnmi"}
+				return nn
+			}(),
+			want: func() *ndddvrv1.NetworkNode {
+				nn := networkNode(withClass("spine"))
+				nn.Spec.DeviceDriverReference = &ndddvrv1.DeviceDriverReference{Name: "gnmi", Namespace: ndddvrv1.DefaultNamespace}
+				return nn
+			}(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			defaultNetworkNode(tc.nn)
+			if diff := cmp.Diff(tc.want, tc.nn); diff != "" {
+				t.Errorf("\n%s\ndefaultNetworkNode(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNetworkNodeValidatorValidate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		nn     *ndddvrv1.NetworkNode
+		want   []string
+	}{
+		"Valid": {
+			reason: "A valid network node should be allowed.",
+			nn:     networkNode(),
+		},
+		"IPv6": {
+			reason: "A network node with an IPv6 address in square brackets should be allowed.",
+			nn:     networkNode(withAddress("[2001:db8::1]:57400")),
+		},
+		"IPv6WithoutBrackets": {
+			reason: "A network node with an IPv6 address without square brackets should be denied.",
+			nn:     networkNode(withAddress("2001:db8::1:57400")),
+			want:   []string{"Invalid value spec.target.address"},
+		},
+		"DNSName": {
+			reason: "A network node with a DNS name should be allowed.",
+			nn:     networkNode(withAddress("leaf1.example.com:57400")),
+		},
+		"InvalidHost": {
+			reason: "A network node with a host that is neither an IP address nor a DNS name should be denied.",
+			nn:     networkNode(withAddress("leaf_1:57400")),
+			want:   []string{"Invalid value spec.target.address"},
+		},
+		"NoPort": {
+			reason: "A network node with an address without port should be denied.",
+			nn:     networkNode(withAddress("10.0.0.1")),
+			want:   []string{"Invalid value spec.target.address"},
+		},
+		"InvalidPort": {
+			reason: "A network node with an address with an invalid port should be denied.",
+			nn:     networkNode(withAddress("10.0.0.1:65536")),
+			want:   []string{"Invalid value spec.target.address"},
+		},
+		"UnknownKind": {
+			reason: "A network node with an unregistered device driver kind should be denied.",
+			nn:     networkNode(withKind("unknown")),
+			want:   []string{"Invalid value spec.deviceDriverKind"},
+		},
+		"RegisteredKind": {
+			reason: "A network node with a device driver kind of the kinds configmap should be allowed with any encoding.",
+			nn:     networkNode(withKind(customKind), withEncoding("PROTO")),
+		},
+		"GnmiEncoding": {
+			reason: "A gnmi network node should be allowed with a gnmi encoding.",
+			nn:     networkNode(withKind(ndddvrv1.DeviceDriverKindGnmi), withEncoding("PROTO")),
+		},
+		"NetconfEncoding": {
+			reason: "A netconf network node should be denied with an encoding other than the default encoding.",
+			nn:     networkNode(withKind(ndddvrv1.DeviceDriverKindNetconf), withEncoding("PROTO")),
+			want:   []string{"Unsupported value spec.target.encoding"},
+		},
+		"NoCredentials": {
+			reason: "A network node without credentials should be denied.",
+			nn:     networkNode(withCredentials(nil)),
+			want:   []string{"Required value spec.target.credentialsName"},
+		},
+		"CredentialsNotFound": {
+			reason: "A network node referencing a credentials secret that does not exist should be denied.",
+			nn:     networkNode(withCredentials(utils.StringPtr("missing"))),
+			want:   []string{"Not found spec.target.credentialsName"},
+		},
+		"Proxy": {
+			reason: "A network node with a valid proxy should be allowed.",
+			nn:     networkNode(withProxy("socks5://jump:1080")),
+		},
+		"ProxyScheme": {
+			reason: "A network node with a proxy of an unsupported scheme should be denied.",
+			nn:     networkNode(withProxy("https://jump:1080")),
+			want:   []string{"Unsupported value spec.target.proxy"},
+		},
+		"ProxyCredentialsWithoutProxy": {
+			reason: "A network node with proxy credentials and without proxy should be denied.",
+			nn:     networkNode(withProxyCredentials("creds")),
+			want:   []string{"Invalid value spec.target.proxyCredentialsName"},
+		},
+		"Class": {
+			reason: "A network node should be validated with the defaults of its network node class.",
+			nn:     networkNode(withClass("custom"), withEncoding("PROTO")),
+		},
+		"ClassNotFound": {
+			reason: "A network node referencing a network node class that does not exist should be denied.",
+			nn:     networkNode(withClass("missing")),
+			want:   []string{"Not found spec.networkNodeClassName"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := newFakeClient(t, networkNodeClass("custom", customKind))
+			v := &NetworkNodeValidator{client: c, kinds: nn.NewConfigMapKindRegistry(c, ndddvrv1.Namespace), log: logging.NewNopLogger()}
+			errs, err := v.validate(context.Background(), tc.nn)
+			if err != nil {
+				t.Fatalf("\n%s\nvalidate(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, errorFields(errs)); diff != "" {
+				t.Errorf("\n%s\nvalidate(...): -want errors, +got errors:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNetworkNodeValidatorValidateUpdate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		old    *ndddvrv1.NetworkNode
+		nn     *ndddvrv1.NetworkNode
+		want   []string
+	}{
+		"Unchanged": {
+			reason: "A network node of which the device driver kind is unchanged should be allowed.",
+			old:    networkNode(withKind(ndddvrv1.DeviceDriverKindGnmi)),
+			nn:     networkNode(withKind(ndddvrv1.DeviceDriverKindGnmi), withAddress("10.0.0.2:57400")),
+		},
+		"UnsetToDefault": {
+			reason: "A network node of which the device driver kind is unset to the same default kind should be allowed.",
+			old:    networkNode(withKind(ndddvrv1.DeviceDriverKindGnmi)),
+			nn:     networkNode(),
+		},
+		"Changed": {
+			reason: "A network node of which the device driver kind changes should be denied.",
+			old:    networkNode(withKind(ndddvrv1.DeviceDriverKindGnmi)),
+			nn:     networkNode(withKind(ndddvrv1.DeviceDriverKindNetconf)),
+			want:   []string{"Forbidden spec.deviceDriverKind"},
+		},
+		"ChangedThroughClass": {
+			reason: "A network node of which the device driver kind changes through its network node class should be denied.",
+			old:    networkNode(withClass("gnmi")),
+			nn:     networkNode(withClass("netconf")),
+			want:   []string{"Forbidden spec.deviceDriverKind"},
+		},
+		"ClassNotFound": {
+			reason: "A network node of which the network node class does not exist should not be compared.",
+			old:    networkNode(withClass("missing")),
+			nn:     networkNode(withKind(ndddvrv1.DeviceDriverKindNetconf)),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := newFakeClient(t,
+				networkNodeClass("gnmi", ndddvrv1.DeviceDriverKindGnmi),
+				networkNodeClass("netconf", ndddvrv1.DeviceDriverKindNetconf))
+			v := &NetworkNodeValidator{client: c, kinds: nn.NewConfigMapKindRegistry(c, ndddvrv1.Namespace), log: logging.NewNopLogger()}
+			errs, err := v.validateUpdate(context.Background(), tc.old, tc.nn)
+			if err != nil {
+				t.Fatalf("\n%s\nvalidateUpdate(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, errorFields(errs)); diff != "" {
+				t.Errorf("\n%s\nvalidateUpdate(...): -want errors, +got errors:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
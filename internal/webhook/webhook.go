@@ -0,0 +1,258 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+
+	"github.com/netw-device-driver/ndd-core/internal/pki"
+	"github.com/netw-device-driver/ndd-core/internal/webhook/dvr"
+)
+
+const (
+	// ServiceName is the name of the service of the webhook server
+	ServiceName = "ndd-webhook-service"
+
+	// the webhook configurations of the core
+	mutatingWebhookConfiguration   = "ndd-mutating-webhook-configuration"
+	validatingWebhookConfiguration = "ndd-validating-webhook-configuration"
+
+	// the webhook path of the conversions of the core resources
+	pathConvert = "/convert"
+
+	certificateRenewal = 1 * time.Hour
+)
+
+// conversionCRDs are the custom resource definitions of the core that are
+// converted by the conversion webhook of the core
+var conversionCRDs = []string{
+	"networknodes.dvr.ndd.yndd.io",
+	"devicedrivers.dvr.ndd.yndd.io",
+}
+
+const (
+	errNoNamespace       = "no namespace for the webhook server"
+	errSetupDvr          = "cannot setup dvr webhooks"
+	errLoadCA            = "cannot load certificate authority"
+	errNoCA              = "certificate authority does not exist"
+	errIssueCert         = "cannot issue webhook server certificate"
+	errWriteCert         = "cannot write webhook server certificate"
+	errGetWebhookConfig  = "cannot get webhook configuration"
+	errGetCRD            = "cannot get custom resource definition"
+	errInjectCABundle    = "cannot inject ca bundle in webhook configuration"
+	errAddCertRenewal    = "cannot add webhook server certificate renewal"
+	errReconcileWebhooks = "cannot reconcile webhook certificate"
+)
+
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;list;watch;update;patch
+
+// Setup registers the webhooks of the core with the webhook server of the
+// supplied manager. The certificate of the webhook server is issued by the
+// certificate authority of the core, which is injected as ca bundle in the
+// webhook configurations. The webhooks are not served when the certificate
+// authority does not exist, e.g. when the core is upgraded without running the
+// initializer that creates it, such that the core keeps running.
+func Setup(mgr ctrl.Manager, l logging.Logger, namespace string) error {
+	if namespace == "" {
+		return errors.New(errNoNamespace)
+	}
+	ca, err := pki.LoadCA(context.Background(), mgr.GetAPIReader(), namespace)
+	if err != nil {
+		return errors.Wrap(err, errLoadCA)
+	}
+	if ca == nil {
+		l.Info(errNoCA + ", the webhooks are not served until the core restarts with a certificate authority")
+		return nil
+	}
+	if err := dvr.Setup(mgr, l, namespace); err != nil {
+		return errors.Wrap(err, errSetupDvr)
+	}
+	mgr.GetWebhookServer().Register(pathConvert, &conversion.Webhook{})
+
+	c := &certificate{
+		reader:    mgr.GetAPIReader(),
+		client:    mgr.GetClient(),
+		log:       l,
+		namespace: namespace,
+		certDir:   mgr.GetWebhookServer().CertDir,
+	}
+	// the webhook server loads the certificate when it starts
+	if err := c.reconcile(context.Background()); err != nil {
+		return errors.Wrap(err, errReconcileWebhooks)
+	}
+	return errors.Wrap(mgr.Add(c), errAddCertRenewal)
+}
+
+// A certificate issues the certificate of the webhook server and injects the
+// certificate authority in the webhook configurations.
+type certificate struct {
+	reader    client.Reader
+	client    client.Client
+	log       logging.Logger
+	namespace string
+	certDir   string
+}
+
+// NeedLeaderElection returns false, every replica of the core runs a webhook
+// server.
+func (c *certificate) NeedLeaderElection() bool {
+	return false
+}
+
+// Start renews the certificate of the webhook server until the context is
+// done, the webhook server reloads the certificate when it changes.
+func (c *certificate) Start(ctx context.Context) error {
+	t := time.NewTicker(certificateRenewal)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+			if err := c.reconcile(ctx); err != nil {
+				c.log.Debug(errReconcileWebhooks, "error", err)
+			}
+		}
+	}
+}
+
+func (c *certificate) reconcile(ctx context.Context) error {
+	ca, err := pki.LoadCA(ctx, c.reader, c.namespace)
+	if err != nil {
+		return errors.Wrap(err, errLoadCA)
+	}
+	if ca == nil {
+		return errors.New(errNoCA)
+	}
+	if err := c.writeCertificate(ca); err != nil {
+		return err
+	}
+	return c.injectCABundle(ctx, ca.CertPEM())
+}
+
+// writeCertificate writes the certificate of the webhook server to the
+// certificate directory of the webhook server, the current certificate is kept
+// when it is valid.
+func (c *certificate) writeCertificate(ca *pki.CA) error {
+	current := map[string][]byte{}
+	for _, k := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey, pki.KeyCACert} {
+		// a missing file results in a new certificate
+		b, _ := os.ReadFile(filepath.Join(c.certDir, k))
+		current[k] = b
+	}
+	data, err := ca.ServingCertificate(current, ServiceName, pki.ServiceDNSNames([]string{ServiceName}, c.namespace))
+	if err != nil {
+		return errors.Wrap(err, errIssueCert)
+	}
+	if err := os.MkdirAll(c.certDir, 0700); err != nil {
+		return errors.Wrap(err, errWriteCert)
+	}
+	// the key is written before the certificate, since the webhook server
+	// reloads the key pair when the certificate changes
+	for _, k := range []string{corev1.TLSPrivateKeyKey, pki.KeyCACert, corev1.TLSCertKey} {
+		if bytes.Equal(current[k], data[k]) {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(c.certDir, k), data[k], 0600); err != nil {
+			return errors.Wrap(err, errWriteCert)
+		}
+	}
+	return nil
+}
+
+// injectCABundle injects the certificate of the certificate authority in the
+// webhook configurations and the conversion webhooks of the custom resource
+// definitions of the core, the ones that do not exist are skipped.
+func (c *certificate) injectCABundle(ctx context.Context, caBundle []byte) error {
+	m := &admissionv1.MutatingWebhookConfiguration{}
+	err := c.reader.Get(ctx, types.NamespacedName{Name: mutatingWebhookConfiguration}, m)
+	if resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errGetWebhookConfig)
+	}
+	if err == nil {
+		changed := false
+		for i := range m.Webhooks {
+			if !bytes.Equal(m.Webhooks[i].ClientConfig.CABundle, caBundle) {
+				m.Webhooks[i].ClientConfig.CABundle = caBundle
+				changed = true
+			}
+		}
+		if changed {
+			if err := c.client.Update(ctx, m); err != nil {
+				return errors.Wrap(err, errInjectCABundle)
+			}
+		}
+	}
+
+	v := &admissionv1.ValidatingWebhookConfiguration{}
+	err = c.reader.Get(ctx, types.NamespacedName{Name: validatingWebhookConfiguration}, v)
+	if resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errGetWebhookConfig)
+	}
+	if err == nil {
+		changed := false
+		for i := range v.Webhooks {
+			if !bytes.Equal(v.Webhooks[i].ClientConfig.CABundle, caBundle) {
+				v.Webhooks[i].ClientConfig.CABundle = caBundle
+				changed = true
+			}
+		}
+		if changed {
+			if err := c.client.Update(ctx, v); err != nil {
+				return errors.Wrap(err, errInjectCABundle)
+			}
+		}
+	}
+
+	for _, name := range conversionCRDs {
+		crd := &extv1.CustomResourceDefinition{}
+		err := c.reader.Get(ctx, types.NamespacedName{Name: name}, crd)
+		if resource.IgnoreNotFound(err) != nil {
+			return errors.Wrap(err, errGetCRD)
+		}
+		if err != nil {
+			continue
+		}
+		cv := crd.Spec.Conversion
+		if cv == nil || cv.Strategy != extv1.WebhookConverter || cv.Webhook == nil || cv.Webhook.ClientConfig == nil {
+			continue
+		}
+		if bytes.Equal(cv.Webhook.ClientConfig.CABundle, caBundle) {
+			continue
+		}
+		cv.Webhook.ClientConfig.CABundle = caBundle
+		if err := c.client.Update(ctx, crd); err != nil {
+			return errors.Wrap(err, errInjectCABundle)
+		}
+	}
+	return nil
+}